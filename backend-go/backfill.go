@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/leader"
+	"backend/internal/models"
+	"backend/internal/poller"
+	"backend/internal/storage"
+)
+
+// runBackfill implements the backfill subcommand: it stores every review the
+// feeds of one app still serve, without starting the HTTP server or the poller.
+// Stop the service first, both write the same storage file. With leader
+// election the backfill refuses to run while an instance holds the lease.
+//
+//	backend backfill -app 389801252 [-countries us,de]
+func runBackfill(args []string) int {
+	flags := flag.NewFlagSet("backfill", flag.ExitOnError)
+	appID := flags.String("app", "", "iTunes app ID to backfill (required)")
+	countries := flags.String("countries", "", "comma separated storefront codes, defaults to the app's configured countries")
+	flags.Parse(args)
+
+	logger := log.New(os.Stdout, "[BACKFILL] ", log.LstdFlags)
+
+	if *appID == "" {
+		fmt.Fprintln(os.Stderr, "backfill: -app is required")
+		flags.Usage()
+		return 2
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		logger.Printf("Failed to load config: %v", err)
+		return 1
+	}
+
+	if leaderRunning(cfg, logger) {
+		return 1
+	}
+
+	store, err := storage.NewFileStorage(storageFilePath)
+	if err != nil {
+		logger.Printf("Failed to create storage: %v", err)
+		return 1
+	}
+	if err := store.LoadState(); err != nil {
+		logger.Printf("Failed to load existing state: %v", err)
+		return 1
+	}
+
+	// Use the configured app if it is tracked, so its countries apply
+	app := models.App{ID: *appID}
+	for _, tracked := range cfg.Apps {
+		if tracked.ID == *appID {
+			app = tracked
+			break
+		}
+	}
+	if *countries != "" {
+		app.Countries = strings.Split(*countries, ",")
+	}
+
+	httpClient, err := newHTTPClient(cfg, logger)
+	if err != nil {
+		logger.Printf("Failed to create HTTP client: %v", err)
+		return 1
+	}
+
+	// The poller is only used for its HTTP client with retries and rate limit
+	backfiller := poller.NewPoller(store, logger, nil, time.Duration(cfg.Poller.Interval), newPollerOptions(cfg, httpClient)...)
+	result, err := backfiller.Backfill(app, app.StoreCountries())
+
+	logger.Printf("Backfilled app %s: %d reviews fetched, %d added (feed entries: %v)", app.ID, result.Fetched, result.Added, result.Parse)
+	if err != nil {
+		logger.Printf("Backfill incomplete: %v", err)
+		return 1
+	}
+	return 0
+}
+
+// leaderRunning reports whether, with leader election, an instance holds an
+// unexpired lease and so writes the storage file. A lease that cannot be read
+// counts as held. The reason is logged.
+func leaderRunning(cfg *config.Config, logger *log.Logger) bool {
+	if !cfg.Leader.Enabled {
+		return false
+	}
+
+	lease, err := leader.ReadLease(cfg.Leader.LockFile)
+	if err != nil {
+		logger.Printf("Failed to check leader lease: %v", err)
+		return true
+	}
+	if !lease.Expired(time.Now()) {
+		logger.Printf("Instance %s leads until %s and writes the same storage, stop it first", lease.Holder, lease.ExpiresAt.Format(time.RFC3339))
+		return true
+	}
+	return false
+}
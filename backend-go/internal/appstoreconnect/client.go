@@ -0,0 +1,233 @@
+package appstoreconnect
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/httpclient"
+	"backend/internal/models"
+)
+
+// DefaultBaseURL is the host of the App Store Connect API
+const DefaultBaseURL = "https://api.appstoreconnect.apple.com"
+
+// pageSize is the number of reviews requested per page (the API maximum)
+const pageSize = 200
+
+// Config identifies the App Store Connect API key requests are signed with
+type Config struct {
+	KeyFile  string // .p8 private key downloaded from App Store Connect
+	KeyID    string
+	IssuerID string
+}
+
+// Client reads customer reviews and their developer responses from the App
+// Store Connect API
+type Client struct {
+	baseURL   string
+	client    *http.Client
+	userAgent string
+	tokens    *tokenSource
+}
+
+// Option customizes a Client created by NewClient
+type Option func(*Client)
+
+// WithBaseURL overrides the API host (e.g. to test against a local fake)
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithHTTPClient sends the requests through client, e.g. one built by
+// httpclient.New with a proxy or custom CA
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// WithUserAgent sets the User-Agent header of the requests
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// NewClient loads the API key named in config
+func NewClient(config Config, opts ...Option) (*Client, error) {
+	privateKey, err := loadPrivateKey(config.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		baseURL: DefaultBaseURL,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		userAgent: httpclient.DefaultUserAgent,
+		tokens: &tokenSource{
+			keyID:      config.KeyID,
+			issuerID:   config.IssuerID,
+			privateKey: privateKey,
+			now:        time.Now,
+		},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// CustomerReview is a review as listed by App Store Connect
+type CustomerReview struct {
+	ID        string
+	Rating    int
+	Title     string
+	Body      string
+	Reviewer  string
+	Territory string // ISO 3166-1 alpha-3 code, e.g. "USA"
+	CreatedAt time.Time
+	Response  *models.DeveloperResponse // nil while unanswered
+}
+
+// customerReviewsResponse is the JSON:API body of the customerReviews
+// endpoint with the response relationship included
+type customerReviewsResponse struct {
+	Data []struct {
+		ID         string `json:"id"`
+		Attributes struct {
+			Rating           int       `json:"rating"`
+			Title            string    `json:"title"`
+			Body             string    `json:"body"`
+			ReviewerNickname string    `json:"reviewerNickname"`
+			Territory        string    `json:"territory"`
+			CreatedDate      time.Time `json:"createdDate"`
+		} `json:"attributes"`
+		Relationships struct {
+			Response struct {
+				Data *struct {
+					ID string `json:"id"`
+				} `json:"data"`
+			} `json:"response"`
+		} `json:"relationships"`
+	} `json:"data"`
+	Included []struct {
+		Type       string `json:"type"`
+		ID         string `json:"id"`
+		Attributes struct {
+			ResponseBody     string    `json:"responseBody"`
+			State            string    `json:"state"`
+			LastModifiedDate time.Time `json:"lastModifiedDate"`
+		} `json:"attributes"`
+	} `json:"included"`
+	Links struct {
+		Next string `json:"next"`
+	} `json:"links"`
+}
+
+// errorResponse is the JSON:API body of a failed request
+type errorResponse struct {
+	Errors []struct {
+		Detail string `json:"detail"`
+	} `json:"errors"`
+}
+
+// CustomerReviews returns the newest reviews of an app with their developer
+// responses, walking at most maxPages pages. The pages are fetched as one
+// httpclient sequence, so a replay does not mix pages of different syncs.
+func (c *Client) CustomerReviews(ctx context.Context, appID string, maxPages int) ([]CustomerReview, error) {
+	ctx = httpclient.WithSequence(ctx, "customerReviews "+appID)
+	query := neturl.Values{
+		"include": {"response"},
+		"sort":    {"-createdDate"},
+		"limit":   {strconv.Itoa(pageSize)},
+	}
+	url := fmt.Sprintf("%s/v1/apps/%s/customerReviews?%s", c.baseURL, neturl.PathEscape(appID), query.Encode())
+
+	var reviews []CustomerReview
+	for page := 1; page <= maxPages && url != ""; page++ {
+		body, err := c.get(ctx, url)
+		if err != nil {
+			return reviews, fmt.Errorf("failed to fetch page %d: %w", page, err)
+		}
+		reviews = append(reviews, body.reviews()...)
+		url = body.Links.Next
+	}
+	return reviews, nil
+}
+
+// reviews joins the reviews of a page with their included responses
+func (r *customerReviewsResponse) reviews() []CustomerReview {
+	responses := make(map[string]*models.DeveloperResponse, len(r.Included))
+	for _, included := range r.Included {
+		if included.Type != "customerReviewResponses" {
+			continue
+		}
+		responses[included.ID] = &models.DeveloperResponse{
+			Body:           included.Attributes.ResponseBody,
+			State:          included.Attributes.State,
+			LastModifiedAt: included.Attributes.LastModifiedDate,
+		}
+	}
+
+	reviews := make([]CustomerReview, 0, len(r.Data))
+	for _, entry := range r.Data {
+		review := CustomerReview{
+			ID:        entry.ID,
+			Rating:    entry.Attributes.Rating,
+			Title:     entry.Attributes.Title,
+			Body:      entry.Attributes.Body,
+			Reviewer:  entry.Attributes.ReviewerNickname,
+			Territory: entry.Attributes.Territory,
+			CreatedAt: entry.Attributes.CreatedDate,
+		}
+		if related := entry.Relationships.Response.Data; related != nil {
+			review.Response = responses[related.ID]
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews
+}
+
+func (c *Client) get(ctx context.Context, url string) (*customerReviewsResponse, error) {
+	token, err := c.tokens.bearer()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body errorResponse
+		if json.NewDecoder(resp.Body).Decode(&body) == nil && len(body.Errors) > 0 {
+			return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, body.Errors[0].Detail)
+		}
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var body customerReviewsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode customer reviews: %w", err)
+	}
+	return &body, nil
+}
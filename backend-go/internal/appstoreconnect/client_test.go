@@ -0,0 +1,259 @@
+package appstoreconnect
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeConnect serves the customerReviews endpoint of App Store Connect for
+// app 123, pageSize reviews per page. Every second review has a response.
+type fakeConnect struct {
+	*httptest.Server
+	t         *testing.T
+	publicKey *ecdsa.PublicKey
+	reviewIDs []string
+	pageSize  int
+
+	mu       sync.Mutex
+	requests int
+	tokens   map[string]bool
+}
+
+func newFakeConnect(t *testing.T, publicKey *ecdsa.PublicKey, reviewIDs []string, pageSize int) *fakeConnect {
+	f := &fakeConnect{t: t, publicKey: publicKey, reviewIDs: reviewIDs, pageSize: pageSize, tokens: make(map[string]bool)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/apps/{id}/customerReviews", f.handleReviews)
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+// verify checks the ES256 signature and the claims of a bearer token
+func (f *fakeConnect) verify(token string) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	if len(signature) != 64 {
+		f.t.Errorf("Expected a 64 byte raw ES256 signature, got %d bytes", len(signature))
+		return false
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	if !ecdsa.Verify(f.publicKey, digest[:], r, s) {
+		return false
+	}
+
+	var header map[string]string
+	headerJSON, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	json.Unmarshal(headerJSON, &header)
+	if header["alg"] != "ES256" || header["kid"] != "KEY123" {
+		f.t.Errorf("Unexpected JWT header %v", header)
+	}
+
+	var claims map[string]any
+	claimsJSON, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	json.Unmarshal(claimsJSON, &claims)
+	if claims["iss"] != "issuer-uuid" || claims["aud"] != tokenAudience {
+		f.t.Errorf("Unexpected JWT claims %v", claims)
+	}
+	if lifetime := claims["exp"].(float64) - claims["iat"].(float64); lifetime > 20*60 {
+		f.t.Errorf("Expected a token lifetime of at most 20 minutes, got %vs", lifetime)
+	}
+	return true
+}
+
+func (f *fakeConnect) handleReviews(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests++
+	f.mu.Unlock()
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !f.verify(token) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"errors": [{"status": "401", "code": "NOT_AUTHORIZED", "detail": "Provide a properly configured and signed bearer token"}]}`)
+		return
+	}
+	f.mu.Lock()
+	f.tokens[token] = true
+	f.mu.Unlock()
+
+	if r.PathValue("id") != "123" {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"errors": [{"status": "404", "code": "NOT_FOUND", "detail": "There is no resource of type 'apps' with id '999'"}]}`)
+		return
+	}
+	if r.URL.Query().Get("include") != "response" {
+		f.t.Errorf("Expected the response relationship to be included, got %q", r.URL.RawQuery)
+	}
+
+	start := 0
+	fmt.Sscanf(r.URL.Query().Get("cursor"), "%d", &start)
+	end := min(start+f.pageSize, len(f.reviewIDs))
+
+	var data, included []string
+	for i, id := range f.reviewIDs[start:end] {
+		response := "null"
+		if (start+i)%2 == 0 {
+			response = fmt.Sprintf(`{"type": "customerReviewResponses", "id": "response-%s"}`, id)
+			included = append(included, fmt.Sprintf(`{
+				"type": "customerReviewResponses", "id": "response-%s",
+				"attributes": {"responseBody": "Thanks %s", "lastModifiedDate": "2025-01-02T09:00:00-08:00", "state": "PUBLISHED"}
+			}`, id, id))
+		}
+		data = append(data, fmt.Sprintf(`{
+			"type": "customerReviews", "id": %q,
+			"attributes": {"rating": 4, "title": "Title", "body": "Body", "reviewerNickname": "User %d", "createdDate": "2025-01-01T10:00:00-08:00", "territory": "USA"},
+			"relationships": {"response": {"data": %s}}
+		}`, id, start+i, response))
+	}
+	next := ""
+	if end < len(f.reviewIDs) {
+		next = fmt.Sprintf(`, "next": "%s/v1/apps/123/customerReviews?include=response&cursor=%d"`, f.URL, end)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"data": [%s], "included": [%s], "links": {"self": "%s"%s}}`,
+		strings.Join(data, ","), strings.Join(included, ","), f.URL, next)
+}
+
+// writeTestKey writes a new P-256 key as a .p8 file
+func writeTestKey(t *testing.T) (string, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+
+	path := filepath.Join(t.TempDir(), "AuthKey_KEY123.p8")
+	os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+	return path, key
+}
+
+func newTestClient(t *testing.T, reviewIDs []string, pageSize int) (*Client, *fakeConnect) {
+	t.Helper()
+	keyFile, key := writeTestKey(t)
+	fake := newFakeConnect(t, &key.PublicKey, reviewIDs, pageSize)
+
+	client, err := NewClient(Config{KeyFile: keyFile, KeyID: "KEY123", IssuerID: "issuer-uuid"}, WithBaseURL(fake.URL))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	return client, fake
+}
+
+func TestClient_CustomerReviews(t *testing.T) {
+	client, fake := newTestClient(t, []string{"r1", "r2", "r3", "r4", "r5"}, 2)
+
+	reviews, err := client.CustomerReviews(context.Background(), "123", 10)
+	if err != nil {
+		t.Fatalf("CustomerReviews failed: %v", err)
+	}
+
+	if len(reviews) != 5 || fake.requests != 3 {
+		t.Fatalf("Expected 5 reviews from 3 pages, got %d reviews from %d requests", len(reviews), fake.requests)
+	}
+	if len(fake.tokens) != 1 {
+		t.Errorf("Expected the token to be reused across pages, got %d tokens", len(fake.tokens))
+	}
+
+	first := reviews[0]
+	if first.ID != "r1" || first.Rating != 4 || first.Reviewer != "User 0" || first.Territory != "USA" {
+		t.Errorf("Unexpected review %+v", first)
+	}
+	response := first.Response
+	if response == nil || response.Body != "Thanks r1" || response.State != "PUBLISHED" {
+		t.Fatalf("Expected the response of r1, got %+v", response)
+	}
+	if !response.LastModifiedAt.Equal(time.Date(2025, 1, 2, 17, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected response timestamp %v", response.LastModifiedAt)
+	}
+	if reviews[1].Response != nil {
+		t.Errorf("Expected r2 to be unanswered, got %+v", reviews[1].Response)
+	}
+	if reviews[2].Response == nil || reviews[2].Response.Body != "Thanks r3" {
+		t.Errorf("Expected the response of r3 from the second page, got %+v", reviews[2].Response)
+	}
+}
+
+func TestClient_CustomerReviewsPageLimit(t *testing.T) {
+	client, fake := newTestClient(t, []string{"r1", "r2", "r3", "r4", "r5"}, 2)
+
+	reviews, err := client.CustomerReviews(context.Background(), "123", 2)
+	if err != nil {
+		t.Fatalf("CustomerReviews failed: %v", err)
+	}
+	if len(reviews) != 4 || fake.requests != 2 {
+		t.Errorf("Expected 4 reviews from 2 pages, got %d reviews from %d requests", len(reviews), fake.requests)
+	}
+}
+
+func TestClient_CustomerReviewsError(t *testing.T) {
+	client, _ := newTestClient(t, nil, 2)
+
+	_, err := client.CustomerReviews(context.Background(), "999", 10)
+	if err == nil || !strings.Contains(err.Error(), "404") || !strings.Contains(err.Error(), "no resource of type 'apps'") {
+		t.Errorf("Expected the status and error detail, got %v", err)
+	}
+}
+
+func TestNewClient_InvalidKey(t *testing.T) {
+	dir := t.TempDir()
+
+	notPEM := filepath.Join(dir, "not-pem.p8")
+	os.WriteFile(notPEM, []byte("not a key"), 0600)
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	der, _ := x509.MarshalPKCS8PrivateKey(rsaKey)
+	notEC := filepath.Join(dir, "rsa.p8")
+	os.WriteFile(notEC, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600)
+
+	for _, path := range []string{filepath.Join(dir, "missing.p8"), notPEM, notEC} {
+		if _, err := NewClient(Config{KeyFile: path, KeyID: "KEY123", IssuerID: "issuer-uuid"}); err == nil {
+			t.Errorf("%s: expected error, got nil", filepath.Base(path))
+		}
+	}
+}
+
+func TestTokenSource_RenewsBeforeExpiry(t *testing.T) {
+	_, key := writeTestKey(t)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tokens := &tokenSource{keyID: "KEY123", issuerID: "issuer-uuid", privateKey: key, now: func() time.Time { return now }}
+
+	first, err := tokens.bearer()
+	if err != nil {
+		t.Fatalf("bearer failed: %v", err)
+	}
+
+	now = now.Add(tokenLifetime - 2*tokenExpiryMargin)
+	if token, _ := tokens.bearer(); token != first {
+		t.Error("Expected the token to be reused while valid")
+	}
+
+	now = now.Add(tokenExpiryMargin)
+	if token, _ := tokens.bearer(); token == first {
+		t.Error("Expected a new token shortly before the old one expires")
+	}
+}
@@ -0,0 +1,192 @@
+package appstoreconnect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+)
+
+// DefaultSyncInterval is how often developer responses are fetched again
+const DefaultSyncInterval = 15 * time.Minute
+
+// maxSyncPages bounds how many review pages are walked per app and sync.
+// Responses to older reviews are not picked up.
+const maxSyncPages = 10
+
+// AppLister provides the apps whose responses are synced
+type AppLister interface {
+	Apps() []models.App
+}
+
+// ResponseStore is where the responses are attached to the stored reviews
+type ResponseStore interface {
+	GetAllReviews() ([]models.Review, error)
+	SetResponses(responses map[string]*models.DeveloperResponse, syncedAt time.Time) error
+}
+
+// ResponseSync keeps the developer responses of the stored reviews of the
+// tracked iOS apps in line with App Store Connect. App Store Connect does not
+// use the review IDs of the RSS feed, so reviews are matched by storefront
+// and reviewer, which the reviewer cannot edit, and by title and text where
+// that is ambiguous. Responses to reviews that are not stored are ignored.
+type ResponseSync struct {
+	client *Client
+	apps   AppLister
+	store  ResponseStore
+	logger *log.Logger
+}
+
+func NewResponseSync(client *Client, apps AppLister, store ResponseStore, logger *log.Logger) *ResponseSync {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &ResponseSync{
+		client: client,
+		apps:   apps,
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Run syncs the responses right away and then every interval until stop is
+// closed. Closing stop aborts a running sync before it changes any more
+// reviews.
+func (s *ResponseSync) Run(interval time.Duration, stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sync(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Printf("Error syncing developer responses: %v", err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync fetches the responses of every tracked iOS app. Apps that fail keep
+// their previous responses. Once ctx is cancelled no further responses are
+// stored.
+func (s *ResponseSync) Sync(ctx context.Context) error {
+	var errs []error
+	for _, app := range s.apps.Apps() {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		if app.StorePlatform() != models.PlatformIOS {
+			continue
+		}
+		if err := s.syncApp(ctx, app.ID); err != nil {
+			errs = append(errs, fmt.Errorf("app %s: %w", app.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *ResponseSync) syncApp(ctx context.Context, appID string) error {
+	reviews, err := s.client.CustomerReviews(ctx, appID, maxSyncPages)
+	if err != nil {
+		return err // A partial walk could miss responses, so nothing is changed
+	}
+
+	stored, err := s.storedReviews(appID)
+	if err != nil {
+		return err
+	}
+
+	listed := make(map[string][]CustomerReview)
+	for _, review := range reviews {
+		key := connectKey(appID, review)
+		listed[key] = append(listed[key], review)
+	}
+
+	responses := make(map[string]*models.DeveloperResponse, len(reviews))
+	answered := 0
+	for key, candidates := range listed {
+		for id, review := range match(stored[key], candidates) {
+			responses[id] = review.Response // nil clears a deleted response
+			if review.Response != nil {
+				answered++
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := s.store.SetResponses(responses, time.Now()); err != nil {
+		return err
+	}
+
+	s.logger.Printf("Synced developer responses of app %s: %d of %d reviews matched, %d answered", appID, len(responses), len(reviews), answered)
+	return nil
+}
+
+// storedReviews returns the stored iOS reviews of an app by their matchKey
+func (s *ResponseSync) storedReviews(appID string) (map[string][]models.Review, error) {
+	all, err := s.store.GetAllReviews()
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := make(map[string][]models.Review)
+	for _, review := range all {
+		if review.AppID != appID || review.Platform == models.PlatformAndroid {
+			continue
+		}
+		key := matchKey(appID, review.Country, review.Author)
+		reviews[key] = append(reviews[key], review)
+	}
+	return reviews, nil
+}
+
+// match pairs the stored reviews with the App Store Connect reviews sharing
+// their matchKey, by stored review ID. A pair that is alone under its key
+// matches even if the review was edited since the feed was polled; otherwise
+// the title and text have to be the same.
+func match(stored []models.Review, listed []CustomerReview) map[string]CustomerReview {
+	matches := make(map[string]CustomerReview)
+	if len(stored) == 1 && len(listed) == 1 {
+		matches[stored[0].ID] = listed[0]
+		return matches
+	}
+	for _, review := range stored {
+		for _, candidate := range listed {
+			if review.Title == candidate.Title && review.Content == candidate.Body {
+				matches[review.ID] = candidate
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// connectKey is the matchKey of a review listed by App Store Connect
+func connectKey(appID string, review CustomerReview) string {
+	return matchKey(appID, storefront(review.Territory), review.Reviewer)
+}
+
+// matchKey identifies a review across the RSS feed and App Store Connect by
+// what the reviewer cannot edit. Each account reviews an app once per
+// storefront, so keys only collide for reviewers sharing a nickname.
+func matchKey(appID, country, author string) string {
+	return strings.Join([]string{appID, strings.ToLower(country), author}, "\x00")
+}
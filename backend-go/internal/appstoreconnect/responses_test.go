@@ -0,0 +1,151 @@
+package appstoreconnect
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+// fakeApps is a fixed AppLister
+type fakeApps []models.App
+
+func (f fakeApps) Apps() []models.App {
+	return f
+}
+
+// feedReview is a review as the poller stores it from the iTunes RSS feed,
+// whose IDs differ from the App Store Connect ones
+func feedReview(id, appID, country, author, submitted string) models.Review {
+	submittedAt, _ := time.Parse(time.RFC3339, submitted)
+	return models.Review{
+		ID:          id,
+		AppID:       appID,
+		Platform:    models.PlatformIOS,
+		Country:     country,
+		Author:      author,
+		Title:       "Title",
+		SubmittedAt: submittedAt,
+		Link:        "https://apps.apple.com/us/review?id=123&type=Purple%20Software",
+	}
+}
+
+func TestResponseSync_Sync(t *testing.T) {
+	// The fake lists "User <n>" reviews from the US created at 2025-01-01T18:00:00Z
+	client, _ := newTestClient(t, []string{"00000001-aaaa", "00000002-bbbb", "00000003-cccc"}, 2)
+	store := testutil.NewMockStorage()
+	store.SaveReviews([]models.Review{
+		feedReview("10993843155", "123", "us", "User 0", "2025-01-01T10:00:00-08:00"),
+		feedReview("10993843156", "123", "us", "User 1", "2025-01-01T13:00:00-05:00"),
+		feedReview("10993843157", "123", "us", "Feed Only", "2025-01-01T10:00:00-08:00"),
+		feedReview("10993843158", "123", "gb", "User 2", "2025-01-01T10:00:00-08:00"),
+	})
+
+	// User 1's response was deleted in App Store Connect since the last sync
+	store.SetResponses(map[string]*models.DeveloperResponse{"10993843156": {Body: "Old reply", State: models.ResponseStatePublished}}, time.Now())
+
+	apps := fakeApps{{ID: "123"}, {ID: "com.example.app", Platform: models.PlatformAndroid}}
+	sync := NewResponseSync(client, apps, store, log.New(io.Discard, "", 0))
+	if err := sync.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	if review, _ := store.GetReview("10993843155"); review.Response == nil || review.Response.Body != "Thanks 00000001-aaaa" {
+		t.Errorf("Expected the response to be attached to the matching feed review, got %+v", review.Response)
+	}
+	if review, _ := store.GetReview("10993843156"); review.Response != nil {
+		t.Errorf("Expected the deleted response to be cleared, got %+v", review.Response)
+	}
+	if review, _ := store.GetReview("10993843157"); review.Response != nil || review.ResponseSyncedAt != nil {
+		t.Errorf("Expected reviews missing from App Store Connect to be left alone, got %+v", review)
+	}
+	if review, _ := store.GetReview("10993843156"); review.ResponseSyncedAt == nil {
+		t.Error("Expected the matched review to be marked as synced")
+	}
+	if review, _ := store.GetReview("10993843158"); review.Response != nil {
+		t.Errorf("Expected a review from another storefront not to match, got %+v", review.Response)
+	}
+	for _, id := range []string{"00000001-aaaa", "00000003-cccc"} {
+		if _, ok := store.GetReview(id); ok {
+			t.Errorf("Expected App Store Connect review %s not to be stored", id)
+		}
+	}
+}
+
+func TestResponseSync_MatchesEditedReviews(t *testing.T) {
+	// The fake lists "User <n>" reviews titled "Title" with the text "Body"
+	client, _ := newTestClient(t, []string{"00000001-aaaa", "00000002-bbbb"}, 2)
+	store := testutil.NewMockStorage()
+
+	// User 1 changed the title and the submission time moved with the edit
+	edited := feedReview("10993843156", "123", "us", "User 1", "2025-03-01T10:00:00-08:00")
+	edited.Title = "Edited title"
+
+	// Another reviewer shares User 0's nickname, only the text tells them apart
+	original := feedReview("10993843155", "123", "us", "User 0", "2025-01-01T10:00:00-08:00")
+	original.Content = "Body"
+	namesake := feedReview("10993843157", "123", "us", "User 0", "2025-01-01T10:00:00-08:00")
+	namesake.Content = "Another body"
+	store.SaveReviews([]models.Review{edited, original, namesake})
+
+	sync := NewResponseSync(client, fakeApps{{ID: "123"}}, store, log.New(io.Discard, "", 0))
+	if err := sync.Sync(context.Background()); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	if review, _ := store.GetReview("10993843156"); review.ResponseSyncedAt == nil {
+		t.Error("Expected the edited review to be matched")
+	}
+	if review, _ := store.GetReview("10993843155"); review.Response == nil || review.Response.Body != "Thanks 00000001-aaaa" {
+		t.Errorf("Expected the response to be attached to the review with the same text, got %+v", review.Response)
+	}
+	if review, _ := store.GetReview("10993843157"); review.ResponseSyncedAt != nil {
+		t.Errorf("Expected the namesake's review not to be matched, got %+v", review)
+	}
+}
+
+func TestResponseSync_FailedAppKeepsResponses(t *testing.T) {
+	client, _ := newTestClient(t, []string{"00000001-aaaa"}, 2)
+	store := testutil.NewMockStorage()
+	store.SaveReviews([]models.Review{feedReview("20000001", "999", "us", "User 0", "2025-01-01T10:00:00-08:00")})
+	store.SetResponses(map[string]*models.DeveloperResponse{"20000001": {Body: "Reply", State: models.ResponseStatePublished}}, time.Now())
+
+	sync := NewResponseSync(client, fakeApps{{ID: "999"}, {ID: "123"}}, store, log.New(io.Discard, "", 0))
+	if err := sync.Sync(context.Background()); err == nil {
+		t.Error("Expected the failed app to be reported")
+	}
+
+	if review, _ := store.GetReview("20000001"); review.Response == nil {
+		t.Error("Expected the failed app to keep its responses")
+	}
+}
+
+func TestResponseSync_CancelledSyncKeepsResponses(t *testing.T) {
+	client, _ := newTestClient(t, []string{"00000001-aaaa"}, 2)
+	store := testutil.NewMockStorage()
+	store.SaveReviews([]models.Review{feedReview("10993843155", "123", "us", "User 0", "2025-01-01T10:00:00-08:00")})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sync := NewResponseSync(client, fakeApps{{ID: "123"}}, store, log.New(io.Discard, "", 0))
+	if err := sync.Sync(ctx); err == nil {
+		t.Error("Expected the cancelled sync to be reported")
+	}
+
+	if review, _ := store.GetReview("10993843155"); review.Response != nil {
+		t.Errorf("Expected a cancelled sync not to store responses, got %+v", review.Response)
+	}
+}
+
+func TestStorefront(t *testing.T) {
+	tests := map[string]string{"USA": "us", "gbr": "gb", "DEU": "de", "XXX": ""}
+	for territory, expected := range tests {
+		if got := storefront(territory); got != expected {
+			t.Errorf("storefront(%q) = %q, expected %q", territory, got, expected)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package appstoreconnect
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// tokenAudience is the aud claim App Store Connect expects
+const tokenAudience = "appstoreconnect-v1"
+
+// tokenLifetime is how long a token is valid, App Store Connect rejects
+// tokens valid for more than 20 minutes
+const tokenLifetime = 20 * time.Minute
+
+// tokenExpiryMargin renews tokens this long before they expire
+const tokenExpiryMargin = time.Minute
+
+// loadPrivateKey reads the .p8 file of an App Store Connect API key, a PEM
+// encoded PKCS#8 P-256 key
+func loadPrivateKey(path string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read App Store Connect key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("App Store Connect key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse App Store Connect key: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok || key.Curve != elliptic.P256() {
+		return nil, errors.New("App Store Connect key is not a P-256 EC key")
+	}
+	return key, nil
+}
+
+// tokenSource signs the ES256 JWTs App Store Connect requests are
+// authenticated with and reuses them until shortly before they expire
+type tokenSource struct {
+	keyID      string
+	issuerID   string
+	privateKey *ecdsa.PrivateKey
+	now        func() time.Time
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// bearer returns a valid token, signing a new one when needed
+func (t *tokenSource) bearer() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	if t.token != "" && now.Before(t.expiry.Add(-tokenExpiryMargin)) {
+		return t.token, nil
+	}
+
+	token, err := t.sign(now)
+	if err != nil {
+		return "", err
+	}
+	t.token = token
+	t.expiry = now.Add(tokenLifetime)
+	return t.token, nil
+}
+
+// sign builds a JWT issued at now, signed with the API key
+func (t *tokenSource) sign(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": "ES256",
+		"kid": t.keyID,
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT header: %w", err)
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss": t.issuerID,
+		"iat": now.Unix(),
+		"exp": now.Add(tokenLifetime).Unix(),
+		"aud": tokenAudience,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT claims: %w", err)
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(claims)
+
+	// JWS wants the raw 32 byte r and s, not the ASN.1 encoding Go produces
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, t.privateKey, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
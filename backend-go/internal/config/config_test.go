@@ -0,0 +1,216 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/poller"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "apps.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad_Success(t *testing.T) {
+	path := writeConfig(t, `{
+		"apps": [{"id": "123", "countries": ["us", "de"], "interval": "1m"}, "456"],
+		"poller": {
+			"interval": "1h",
+			"jitter": 0.2,
+			"adaptive": {"max_factor": 4, "target_new_reviews": 10},
+			"retry": {"max_retries": 5, "base_delay": "250ms", "max_delay": "10s"},
+			"breaker": {"failure_threshold": 2, "cool_down": "1h"},
+			"max_concurrency": 4,
+			"rate_limit": {"requests_per_second": 0.5, "burst": 3}
+		}
+	}`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if len(cfg.Apps) != 2 {
+		t.Fatalf("Expected 2 apps, got %d", len(cfg.Apps))
+	}
+	if cfg.Apps[0].ID != "123" || len(cfg.Apps[0].Countries) != 2 {
+		t.Errorf("Unexpected first app: %+v", cfg.Apps[0])
+	}
+	if cfg.Apps[1].ID != "456" {
+		t.Errorf("Unexpected second app: %+v", cfg.Apps[1])
+	}
+	if time.Duration(cfg.Apps[0].Interval) != time.Minute || cfg.Apps[1].Interval != 0 {
+		t.Errorf("Expected app intervals 1m and default, got %v and %v", cfg.Apps[0].Interval, cfg.Apps[1].Interval)
+	}
+	if time.Duration(cfg.Poller.Interval) != time.Hour || cfg.Poller.Jitter != 0.2 {
+		t.Errorf("Expected default interval 1h with jitter 0.2, got %v and %v", cfg.Poller.Interval, cfg.Poller.Jitter)
+	}
+
+	adaptive := cfg.Poller.Adaptive
+	if adaptive.MaxFactor != 4 || adaptive.TargetNewReviews != 10 {
+		t.Errorf("Expected adaptive factor 4 targeting 10 reviews, got %+v", adaptive)
+	}
+
+	retry := cfg.Poller.Retry
+	if retry.MaxRetries != 5 {
+		t.Errorf("Expected max_retries 5, got %d", retry.MaxRetries)
+	}
+	if time.Duration(retry.BaseDelay) != 250*time.Millisecond {
+		t.Errorf("Expected base_delay 250ms, got %v", time.Duration(retry.BaseDelay))
+	}
+	if time.Duration(retry.MaxDelay) != 10*time.Second {
+		t.Errorf("Expected max_delay 10s, got %v", time.Duration(retry.MaxDelay))
+	}
+
+	breaker := cfg.Poller.Breaker
+	if breaker.FailureThreshold != 2 || time.Duration(breaker.CoolDown) != time.Hour {
+		t.Errorf("Expected breaker threshold 2 and cool-down 1h, got %+v", breaker)
+	}
+
+	if cfg.Poller.MaxConcurrency != 4 {
+		t.Errorf("Expected max_concurrency 4, got %d", cfg.Poller.MaxConcurrency)
+	}
+	if cfg.Poller.RateLimit.RequestsPerSecond != 0.5 || cfg.Poller.RateLimit.Burst != 3 {
+		t.Errorf("Expected rate limit 0.5/s with burst 3, got %+v", cfg.Poller.RateLimit)
+	}
+}
+
+func TestLoad_DefaultsForMissingSettings(t *testing.T) {
+	path := writeConfig(t, `{"apps": ["123"]}`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Poller != Default().Poller {
+		t.Errorf("Expected default poller config %+v, got %+v", Default().Poller, cfg.Poller)
+	}
+	if cfg.Metadata != Default().Metadata {
+		t.Errorf("Expected default metadata config %+v, got %+v", Default().Metadata, cfg.Metadata)
+	}
+	if cfg.HTTP != Default().HTTP {
+		t.Errorf("Expected default http config %+v, got %+v", Default().HTTP, cfg.HTTP)
+	}
+	if cfg.Leader != Default().Leader || cfg.Leader.Enabled {
+		t.Errorf("Expected leader election to be disabled by default, got %+v", cfg.Leader)
+	}
+	if cfg.AppStoreConnect != Default().AppStoreConnect {
+		t.Errorf("Expected default app_store_connect config %+v, got %+v", Default().AppStoreConnect, cfg.AppStoreConnect)
+	}
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing file, got nil")
+	}
+}
+
+func TestLoad_InvalidJSON(t *testing.T) {
+	path := writeConfig(t, `{"apps": [`)
+
+	_, err := Load(path)
+	if err == nil || !strings.Contains(err.Error(), "failed to decode config") {
+		t.Errorf("Expected decode error, got: %v", err)
+	}
+}
+
+func TestLoad_InvalidDuration(t *testing.T) {
+	path := writeConfig(t, `{"poller": {"retry": {"base_delay": "soon"}}}`)
+
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for invalid duration, got nil")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{"missing app id", func(c *Config) { c.Apps = []models.App{{ID: ""}} }},
+		{"duplicate app id", func(c *Config) { c.Apps = []models.App{{ID: "123"}, {ID: "123"}} }},
+		{"negative retries", func(c *Config) { c.Poller.Retry.MaxRetries = -1 }},
+		{"negative delay", func(c *Config) { c.Poller.Retry.BaseDelay = models.Duration(-time.Second) }},
+		{"negative failure threshold", func(c *Config) { c.Poller.Breaker.FailureThreshold = -1 }},
+		{"negative cool-down", func(c *Config) { c.Poller.Breaker.CoolDown = models.Duration(-time.Minute) }},
+		{"zero concurrency", func(c *Config) { c.Poller.MaxConcurrency = 0 }},
+		{"negative rate", func(c *Config) { c.Poller.RateLimit.RequestsPerSecond = -1 }},
+		{"zero burst", func(c *Config) { c.Poller.RateLimit.Burst = 0 }},
+		{"zero interval", func(c *Config) { c.Poller.Interval = 0 }},
+		{"negative app interval", func(c *Config) { c.Apps = []models.App{{ID: "123", Interval: models.Duration(-time.Minute)}} }},
+		{"jitter of 1", func(c *Config) { c.Poller.Jitter = 1 }},
+		{"adaptive factor below 1", func(c *Config) { c.Poller.Adaptive.MaxFactor = 0.5 }},
+		{"adaptive without target", func(c *Config) { c.Poller.Adaptive = AdaptiveConfig{MaxFactor: 4} }},
+		{"watch without check interval", func(c *Config) {
+			c.Reload = ReloadConfig{Watch: true}
+		}},
+		{"recording without dir", func(c *Config) { c.HTTP.Recording = RecordingConfig{Mode: RecordingModeReplay} }},
+		{"unknown recording mode", func(c *Config) { c.HTTP.Recording = RecordingConfig{Mode: "rewind", Dir: "recordings"} }},
+		{"invalid proxy url", func(c *Config) { c.HTTP.ProxyURL = "proxy.example.com:3128" }},
+		{"negative http timeout", func(c *Config) { c.HTTP.Timeout = models.Duration(-time.Second) }},
+		{"empty user agent", func(c *Config) { c.HTTP.UserAgent = " " }},
+		{"leader without lock file", func(c *Config) {
+			c.Leader.Enabled = true
+			c.Leader.LockFile = ""
+		}},
+		{"leader renew interval not below lease", func(c *Config) {
+			c.Leader.Enabled = true
+			c.Leader.RenewInterval = c.Leader.LeaseDuration
+		}},
+		{"app store connect key without key id", func(c *Config) {
+			c.AppStoreConnect.KeyFile = "AuthKey.p8"
+			c.AppStoreConnect.IssuerID = "issuer"
+		}},
+		{"zero app store connect sync interval", func(c *Config) {
+			c.AppStoreConnect = AppStoreConnectConfig{KeyFile: "AuthKey.p8", KeyID: "KEY", IssuerID: "issuer"}
+		}},
+		{"zero metadata refresh interval", func(c *Config) { c.Metadata.RefreshInterval = 0 }},
+		{"unknown platform", func(c *Config) { c.Apps = []models.App{{ID: "123", Platform: "windows"}} }},
+		{"android without google play", func(c *Config) {
+			c.Apps = []models.App{{ID: "com.example.app", Platform: models.PlatformAndroid}}
+		}},
+	}
+
+	for _, test := range tests {
+		cfg := Default()
+		test.modify(&cfg)
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected validation error, got nil", test.name)
+		}
+	}
+
+	cfg := Default()
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected default config to be valid, got: %v", err)
+	}
+
+	cfg.Apps = []models.App{{ID: "com.example.app", Platform: models.PlatformAndroid}}
+	cfg.Poller.GooglePlay.ServiceAccountFile = "service-account.json"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected android app with google play config to be valid, got: %v", err)
+	}
+}
+
+func TestPollerConfig_Options(t *testing.T) {
+	cfg := Default()
+
+	options := cfg.Poller.Options()
+	if len(options) == 0 {
+		t.Fatal("Expected poller options, got none")
+	}
+
+	// Options must be accepted by NewPoller
+	if p := poller.NewPoller(nil, nil, nil, time.Second, options...); p == nil {
+		t.Error("NewPoller returned nil")
+	}
+}
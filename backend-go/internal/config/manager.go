@@ -0,0 +1,341 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/storage"
+)
+
+var (
+	// ErrAppExists is returned when adding an app that is already tracked
+	ErrAppExists = errors.New("app already exists")
+	// ErrAppNotFound is returned when removing an app that is not tracked
+	ErrAppNotFound = errors.New("app not found")
+	// ErrInvalid wraps validation errors of a changed configuration
+	ErrInvalid = errors.New("invalid config")
+)
+
+// Updater is the running poller that configuration changes are applied to
+type Updater interface {
+	SetApps(apps []models.App)
+	SetInterval(interval time.Duration)
+}
+
+// Manager owns the configuration of the running service. Apps can be added
+// and removed at runtime and the whole file can be reloaded; every change is
+// validated first and then handed to the poller.
+type Manager struct {
+	mu      sync.Mutex
+	path    string
+	config  Config
+	modTime time.Time // Modification time of the file when we last read or wrote it
+	updater Updater
+	logger  *log.Logger
+}
+
+// NewManager manages config, which was loaded from path
+func NewManager(path string, config Config, updater Updater, logger *log.Logger) *Manager {
+	if logger == nil {
+		logger = log.Default()
+	}
+	m := &Manager{
+		path:    path,
+		config:  config,
+		updater: updater,
+		logger:  logger,
+	}
+	m.modTime = m.statModTime()
+	return m
+}
+
+// Apps returns the tracked apps
+func (m *Manager) Apps() []models.App {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]models.App(nil), m.config.Apps...)
+}
+
+// AddApp starts tracking an app and returns it as saved, with its ID trimmed
+func (m *Manager) AddApp(app models.App) (models.App, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	app.ID = strings.TrimSpace(app.ID)
+	for _, existing := range m.config.Apps {
+		if existing.ID == app.ID {
+			return models.App{}, fmt.Errorf("%w: %s", ErrAppExists, app.ID)
+		}
+	}
+
+	apps := append(append([]models.App(nil), m.config.Apps...), app)
+	if err := m.apply(apps); err != nil {
+		return models.App{}, err
+	}
+	return app, nil
+}
+
+// RemoveApp stops tracking an app. Its stored reviews are kept.
+func (m *Manager) RemoveApp(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	apps := make([]models.App, 0, len(m.config.Apps))
+	for _, app := range m.config.Apps {
+		if app.ID != id {
+			apps = append(apps, app)
+		}
+	}
+	if len(apps) == len(m.config.Apps) {
+		return fmt.Errorf("%w: %s", ErrAppNotFound, id)
+	}
+
+	return m.apply(apps)
+}
+
+// apply validates, saves and hands out a new list of apps. The caller must hold m.mu.
+func (m *Manager) apply(apps []models.App) error {
+	config := m.config
+	config.Apps = apps
+	if err := config.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	if err := SaveApps(m.path, apps); err != nil {
+		return err
+	}
+
+	m.config = config
+	m.modTime = m.statModTime()
+	if m.updater != nil {
+		m.updater.SetApps(apps)
+	}
+	return nil
+}
+
+// SaveApps replaces the apps in the config file at path and leaves every
+// other byte of the file as it is, so hand-written settings keep their order
+// and formatting. The file is replaced atomically, so a crash never leaves a
+// half written config behind.
+func SaveApps(path string, apps []models.App) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	start, end, empty, err := findApps(data)
+	if err != nil {
+		return fmt.Errorf("failed to decode config: %w", err)
+	}
+
+	var spliced []byte
+	if start < 0 {
+		// No apps yet, add them as the first setting
+		encoded, err := encodeApps(apps, "  ")
+		if err != nil {
+			return err
+		}
+		entry := "\n  \"apps\": " + string(encoded)
+		if !empty {
+			entry += ","
+		} else {
+			entry += "\n"
+		}
+		spliced = slices.Concat(data[:end], []byte(entry), data[end:])
+	} else {
+		encoded, err := encodeApps(apps, lineIndent(data, start))
+		if err != nil {
+			return err
+		}
+		spliced = slices.Concat(data[:start], encoded, data[end:])
+	}
+
+	return storage.WriteFileAtomic(path, spliced)
+}
+
+// findApps returns the offsets of the apps value in the config file data. If
+// there is none, start is -1, end is the offset right after the opening brace
+// and empty reports whether the object has no settings at all.
+func findApps(data []byte) (start, end int, empty bool, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return 0, 0, false, errors.New("config is not a JSON object")
+	}
+	afterBrace := int(decoder.InputOffset())
+
+	empty = true
+	for decoder.More() {
+		empty = false
+		key, err := decoder.Token()
+		if err != nil {
+			return 0, 0, false, err
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return 0, 0, false, err
+		}
+		if key == "apps" {
+			end := int(decoder.InputOffset())
+			return end - len(value), end, false, nil
+		}
+	}
+	return -1, afterBrace, empty, nil
+}
+
+// encodeApps formats apps one per line, indented one level deeper than indent
+func encodeApps(apps []models.App, indent string) ([]byte, error) {
+	if len(apps) == 0 {
+		return []byte("[]"), nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i, app := range apps {
+		encoded, err := json.Marshal(app)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal apps: %w", err)
+		}
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n" + indent + "  ")
+		buf.Write(encoded)
+	}
+	buf.WriteString("\n" + indent + "]")
+	return buf.Bytes(), nil
+}
+
+// lineIndent returns the leading whitespace of the line containing offset
+func lineIndent(data []byte, offset int) string {
+	lineStart := bytes.LastIndexByte(data[:offset], '\n') + 1
+	line := data[lineStart:offset]
+	return string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+}
+
+// Reload reads the config file again and applies the tracked apps and the
+// default interval to the poller. An invalid file leaves the current
+// configuration in place.
+func (m *Manager) Reload() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	modTime := m.statModTime()
+	config, err := Load(m.path)
+	if err != nil {
+		return err
+	}
+
+	previous := m.config
+	m.config = *config
+	m.modTime = modTime
+
+	added, removed, changed := diffApps(previous.Apps, config.Apps)
+	m.logger.Printf("Config reloaded: %d apps added, %d removed, %d changed", added, removed, changed)
+
+	restartOnly := previous.Poller
+	restartOnly.Interval = config.Poller.Interval
+	if restartOnly != config.Poller {
+		m.logger.Println("Warning: poller settings other than interval only take effect after a restart")
+	}
+	if previous.HTTP != config.HTTP || previous.Metadata != config.Metadata || previous.Leader != config.Leader ||
+		previous.AppStoreConnect != config.AppStoreConnect {
+		m.logger.Println("Warning: http, metadata, leader and app_store_connect settings only take effect after a restart")
+	}
+
+	if m.updater != nil {
+		m.updater.SetInterval(time.Duration(config.Poller.Interval))
+		m.updater.SetApps(config.Apps)
+	}
+	return nil
+}
+
+// Watch reloads the config whenever the file is modified, checking every
+// reload.check_interval while reload.watch is set, until stop is closed.
+// Both settings follow the current config, so a reload can switch watching
+// on or off and change how often the file is checked. Failed reloads are
+// logged and the current configuration stays in place.
+func (m *Manager) Watch(stop <-chan struct{}) {
+	interval := m.watchInterval()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+
+		m.mu.Lock()
+		watch := m.config.Reload.Watch
+		modified := watch && !m.statModTime().Equal(m.modTime)
+		m.mu.Unlock()
+
+		if modified {
+			m.logger.Printf("Config file %s changed, reloading", m.path)
+			if err := m.Reload(); err != nil {
+				m.logger.Printf("Error reloading config, keeping the current one: %v", err)
+				// Do not retry the same broken file on every tick
+				m.mu.Lock()
+				m.modTime = m.statModTime()
+				m.mu.Unlock()
+			}
+		}
+
+		if current := m.watchInterval(); current != interval {
+			interval = current
+			ticker.Reset(interval)
+		}
+	}
+}
+
+// watchInterval returns how often Watch checks the config file. Without
+// watching it still ticks, so a reload can switch watching on.
+func (m *Manager) watchInterval() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if interval := time.Duration(m.config.Reload.CheckInterval); interval > 0 {
+		return interval
+	}
+	return DefaultReloadCheckInterval
+}
+
+// statModTime returns the modification time of the config file, zero if it cannot be read
+func (m *Manager) statModTime() time.Time {
+	info, err := os.Stat(m.path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// diffApps counts the apps added, removed and changed between two app lists
+func diffApps(previous, current []models.App) (added, removed, changed int) {
+	before := make(map[string]models.App, len(previous))
+	for _, app := range previous {
+		before[app.ID] = app
+	}
+
+	for _, app := range current {
+		old, ok := before[app.ID]
+		switch {
+		case !ok:
+			added++
+		case old.StorePlatform() != app.StorePlatform() || old.Interval != app.Interval ||
+			!slices.Equal(old.StoreCountries(), app.StoreCountries()):
+			changed++
+		}
+		delete(before, app.ID)
+	}
+	return added, len(before), changed
+}
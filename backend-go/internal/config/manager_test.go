@@ -0,0 +1,303 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+)
+
+// fakeUpdater records the apps and interval handed to the poller
+type fakeUpdater struct {
+	apps     []models.App
+	interval time.Duration
+	calls    int
+}
+
+func (f *fakeUpdater) SetApps(apps []models.App) {
+	f.apps = apps
+	f.calls++
+}
+
+func (f *fakeUpdater) SetInterval(interval time.Duration) {
+	f.interval = interval
+}
+
+func newTestManager(t *testing.T) (*Manager, *fakeUpdater, string) {
+	t.Helper()
+	path := writeConfig(t, `{
+		"apps": [{"id": "123", "countries": ["us"]}],
+		"poller": {"interval": "1h", "max_concurrency": 3}
+	}`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	updater := &fakeUpdater{}
+	return NewManager(path, *cfg, updater, log.New(io.Discard, "", 0)), updater, path
+}
+
+func TestManager_AddApp(t *testing.T) {
+	manager, updater, path := newTestManager(t)
+
+	app := models.App{ID: " 456 ", Countries: []string{"de"}, Interval: models.Duration(time.Minute)}
+	added, err := manager.AddApp(app)
+	if err != nil {
+		t.Fatalf("AddApp failed: %v", err)
+	}
+	if added.ID != "456" {
+		t.Errorf("Expected the added app with its ID trimmed, got %q", added.ID)
+	}
+
+	if apps := manager.Apps(); len(apps) != 2 || apps[1].ID != "456" {
+		t.Errorf("Expected app 456 to be added, got %+v", apps)
+	}
+	if updater.calls != 1 || len(updater.apps) != 2 {
+		t.Errorf("Expected the poller to get both apps, got %+v", updater.apps)
+	}
+
+	// The file is rewritten with the new apps and the other settings kept
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load after AddApp failed: %v", err)
+	}
+	if len(cfg.Apps) != 2 || cfg.Apps[1].ID != "456" || time.Duration(cfg.Apps[1].Interval) != time.Minute {
+		t.Errorf("Expected app 456 in the config file, got %+v", cfg.Apps)
+	}
+	if time.Duration(cfg.Poller.Interval) != time.Hour || cfg.Poller.MaxConcurrency != 3 {
+		t.Errorf("Expected poller settings to be kept, got %+v", cfg.Poller)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("Expected temp file to be renamed")
+	}
+}
+
+func TestManager_AddAppErrors(t *testing.T) {
+	manager, updater, path := newTestManager(t)
+	before, _ := os.ReadFile(path)
+
+	if _, err := manager.AddApp(models.App{ID: "123"}); !errors.Is(err, ErrAppExists) {
+		t.Errorf("Expected ErrAppExists, got %v", err)
+	}
+	if _, err := manager.AddApp(models.App{ID: "com.example.app", Platform: models.PlatformAndroid}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for an android app without google play, got %v", err)
+	}
+	if _, err := manager.AddApp(models.App{ID: ""}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for a missing id, got %v", err)
+	}
+	if _, err := manager.AddApp(models.App{ID: "../123"}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for a non-numeric ios id, got %v", err)
+	}
+
+	if updater.calls != 0 {
+		t.Errorf("Expected rejected apps not to reach the poller, got %d updates", updater.calls)
+	}
+	if after, _ := os.ReadFile(path); string(after) != string(before) {
+		t.Error("Expected rejected apps not to change the config file")
+	}
+}
+
+func TestManager_RemoveApp(t *testing.T) {
+	manager, updater, path := newTestManager(t)
+
+	if err := manager.RemoveApp("999"); !errors.Is(err, ErrAppNotFound) {
+		t.Errorf("Expected ErrAppNotFound, got %v", err)
+	}
+
+	if err := manager.RemoveApp("123"); err != nil {
+		t.Fatalf("RemoveApp failed: %v", err)
+	}
+	if len(manager.Apps()) != 0 || updater.calls != 1 || len(updater.apps) != 0 {
+		t.Errorf("Expected no apps left, got %+v", manager.Apps())
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"apps": []`) {
+		t.Errorf("Expected an empty app list in the config file, got %s", data)
+	}
+}
+
+func TestSaveApps_KeepsOtherSettings(t *testing.T) {
+	before := "{\n    \"poller\": {\"interval\": \"1h\"},\n    \"apps\": [\"123\"],\n    \"http\":   {\"user_agent\": \"Bot/1.0\"}\n}\n"
+	path := writeConfig(t, before)
+
+	if err := SaveApps(path, []models.App{{ID: "123"}, {ID: "456"}}); err != nil {
+		t.Fatalf("SaveApps failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	after := string(data)
+	if !strings.HasPrefix(after, "{\n    \"poller\": {\"interval\": \"1h\"},\n    \"apps\": [\n      {") ||
+		!strings.HasSuffix(after, "}\n    ],\n    \"http\":   {\"user_agent\": \"Bot/1.0\"}\n}\n") {
+		t.Errorf("Expected only the apps to change, got:\n%s", after)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load after SaveApps failed: %v", err)
+	}
+	if len(cfg.Apps) != 2 || cfg.Apps[1].ID != "456" || cfg.HTTP.UserAgent != "Bot/1.0" {
+		t.Errorf("Expected both apps and the other settings, got %+v", cfg)
+	}
+}
+
+func TestSaveApps_AddsMissingApps(t *testing.T) {
+	for _, before := range []string{`{"poller": {"interval": "1h"}}`, `{}`} {
+		path := writeConfig(t, before)
+
+		if err := SaveApps(path, []models.App{{ID: "123"}}); err != nil {
+			t.Fatalf("SaveApps of %s failed: %v", before, err)
+		}
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load after SaveApps of %s failed: %v", before, err)
+		}
+		if len(cfg.Apps) != 1 || cfg.Apps[0].ID != "123" {
+			t.Errorf("Expected the app to be added to %s, got %+v", before, cfg.Apps)
+		}
+	}
+}
+
+func TestSaveApps_MissingFile(t *testing.T) {
+	if err := SaveApps(t.TempDir()+"/missing.json", nil); err == nil {
+		t.Error("Expected error for missing config file, got nil")
+	}
+}
+
+func TestManager_Reload(t *testing.T) {
+	manager, updater, path := newTestManager(t)
+
+	os.WriteFile(path, []byte(`{
+		"apps": [{"id": "123", "countries": ["us", "de"]}, {"id": "456"}],
+		"poller": {"interval": "10m", "max_concurrency": 3}
+	}`), 0644)
+
+	if err := manager.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	if len(updater.apps) != 2 || len(updater.apps[0].Countries) != 2 || updater.apps[1].ID != "456" {
+		t.Errorf("Expected the reloaded apps to reach the poller, got %+v", updater.apps)
+	}
+	if updater.interval != 10*time.Minute {
+		t.Errorf("Expected the reloaded interval 10m, got %v", updater.interval)
+	}
+	if len(manager.Apps()) != 2 {
+		t.Errorf("Expected the manager to track the reloaded apps, got %+v", manager.Apps())
+	}
+}
+
+func TestManager_ReloadInvalidKeepsConfig(t *testing.T) {
+	manager, updater, path := newTestManager(t)
+
+	os.WriteFile(path, []byte(`{"apps": [{"id": "123"}, {"id": "123"}]}`), 0644)
+
+	if err := manager.Reload(); err == nil {
+		t.Fatal("Expected error for duplicate apps, got nil")
+	}
+	if updater.calls != 0 {
+		t.Errorf("Expected the invalid config not to reach the poller, got %d updates", updater.calls)
+	}
+	if apps := manager.Apps(); len(apps) != 1 || apps[0].ID != "123" {
+		t.Errorf("Expected the previous apps to stay, got %+v", apps)
+	}
+}
+
+// newWatchedManager returns a manager of a config that reload.watch is set to
+// watch, running Watch until the returned stop function is called
+func newWatchedManager(t *testing.T, watch bool) (*Manager, *fakeUpdater, string, func()) {
+	t.Helper()
+	path := writeConfig(t, watchedConfig(watch, "123"))
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	updater := &fakeUpdater{}
+	manager := NewManager(path, *cfg, updater, log.New(io.Discard, "", 0))
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		manager.Watch(stop)
+		close(done)
+	}()
+	return manager, updater, path, func() {
+		close(stop)
+		<-done
+	}
+}
+
+// watchedConfig returns a config of the given apps checked every 10ms
+func watchedConfig(watch bool, apps ...string) string {
+	return fmt.Sprintf(`{"apps": ["%s"], "reload": {"watch": %t, "check_interval": "10ms"}}`, strings.Join(apps, `", "`), watch)
+}
+
+// rewriteConfig replaces the config file, making sure the modification time
+// differs even on coarse filesystem clocks
+func rewriteConfig(path, content string, offset time.Duration) {
+	os.WriteFile(path, []byte(content), 0644)
+	later := time.Now().Add(offset)
+	os.Chtimes(path, later, later)
+}
+
+// waitForApps waits up to a second for the manager to track count apps
+func waitForApps(manager *Manager, count int) bool {
+	deadline := time.Now().Add(time.Second)
+	for len(manager.Apps()) != count && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return len(manager.Apps()) == count
+}
+
+func TestManager_WatchReloadsChangedFile(t *testing.T) {
+	manager, updater, path, stop := newWatchedManager(t, true)
+
+	rewriteConfig(path, watchedConfig(true, "123", "456"), time.Second)
+	reloaded := waitForApps(manager, 2)
+	stop()
+
+	if !reloaded {
+		t.Errorf("Expected the changed file to be reloaded, got %+v", manager.Apps())
+	}
+	if updater.calls != 1 {
+		t.Errorf("Expected a single reload, got %d", updater.calls)
+	}
+}
+
+func TestManager_WatchFollowsReloadSettings(t *testing.T) {
+	manager, _, path, stop := newWatchedManager(t, false)
+	defer stop()
+
+	rewriteConfig(path, watchedConfig(false, "123", "456"), time.Second)
+	if waitForApps(manager, 2) {
+		t.Fatal("Expected the file not to be watched with reload.watch off")
+	}
+
+	// A reload that switches watching on takes effect right away
+	rewriteConfig(path, watchedConfig(true, "123"), 2*time.Second)
+	if err := manager.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	rewriteConfig(path, watchedConfig(true, "123", "456"), 3*time.Second)
+	if !waitForApps(manager, 2) {
+		t.Errorf("Expected the file to be watched once reload.watch is on, got %+v", manager.Apps())
+	}
+
+	// And one that switches it off again stops watching
+	rewriteConfig(path, watchedConfig(false, "123"), 4*time.Second)
+	if !waitForApps(manager, 1) {
+		t.Fatalf("Expected the file to be reloaded, got %+v", manager.Apps())
+	}
+	rewriteConfig(path, watchedConfig(false, "123", "456"), 5*time.Second)
+	if waitForApps(manager, 2) {
+		t.Error("Expected watching to stop once reload.watch is off")
+	}
+}
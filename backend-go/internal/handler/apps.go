@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"backend/internal/config"
+	"backend/internal/models"
+)
+
+// AppRegistry manages the tracked apps at runtime
+type AppRegistry interface {
+	Apps() []models.App
+	AddApp(app models.App) (models.App, error) // Returns the app as stored
+	RemoveApp(id string) error
+}
+
+// MetadataRegistry provides the store metadata of apps
+type MetadataRegistry interface {
+	Get(appID string) (models.AppMetadata, bool)
+}
+
+// WithMetadata adds the store metadata (name, developer, icon, ...) to the apps
+// served by /api/apps
+func WithMetadata(metadata MetadataRegistry) Option {
+	return func(h *Handler) {
+		h.metadata = metadata
+	}
+}
+
+// appResponse is a tracked app together with its store metadata, when known
+type appResponse struct {
+	models.App
+	Metadata *models.AppMetadata `json:"metadata,omitempty"`
+}
+
+// WithApps enables the endpoints that list, add and remove tracked apps
+func WithApps(apps AppRegistry) Option {
+	return func(h *Handler) {
+		h.apps = apps
+	}
+}
+
+// HandleApps handles GET and POST /api/apps
+// GET lists the tracked apps, POST starts tracking the app in the request body,
+// e.g. {"id": "389801252", "countries": ["us", "de"], "interval": "10m"}.
+func (h *Handler) HandleApps(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.apps == nil {
+		http.Error(w, "App management is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		apps := h.apps.Apps()
+		response := make([]appResponse, 0, len(apps))
+		for _, app := range apps {
+			response = append(response, h.withMetadata(app))
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"apps": response})
+		return
+	}
+	if h.rejectOnFollower(w) {
+		return
+	}
+
+	var app models.App
+	if err := json.NewDecoder(r.Body).Decode(&app); err != nil {
+		http.Error(w, "Invalid app: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	added, err := h.apps.AddApp(app)
+	if err != nil {
+		writeAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, added)
+}
+
+// HandleApp handles GET and DELETE /api/apps/{id}
+// GET returns the tracked app with its store metadata, DELETE stops tracking
+// the app. Its stored reviews are kept.
+func (h *Handler) HandleApp(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.apps == nil {
+		http.Error(w, "App management is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		id := r.PathValue("id")
+		for _, app := range h.apps.Apps() {
+			if app.ID == id {
+				writeJSON(w, http.StatusOK, h.withMetadata(app))
+				return
+			}
+		}
+		http.Error(w, "App not found", http.StatusNotFound)
+		return
+	}
+	if h.rejectOnFollower(w) {
+		return
+	}
+
+	if err := h.apps.RemoveApp(r.PathValue("id")); err != nil {
+		writeAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// withMetadata attaches the store metadata of the app, if any
+func (h *Handler) withMetadata(app models.App) appResponse {
+	response := appResponse{App: app}
+	if h.metadata != nil {
+		if metadata, ok := h.metadata.Get(app.ID); ok {
+			response.Metadata = &metadata
+		}
+	}
+	return response
+}
+
+// writeAppError maps an error of an app change to a response
+func writeAppError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, config.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, config.ErrAppExists):
+		http.Error(w, "App already exists", http.StatusConflict)
+	case errors.Is(err, config.ErrAppNotFound):
+		http.Error(w, "App not found", http.StatusNotFound)
+	default:
+		log.Printf("Error updating apps: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+// fakeApps is an in-memory AppRegistry
+type fakeApps struct {
+	apps []models.App
+}
+
+func (f *fakeApps) Apps() []models.App {
+	return f.apps
+}
+
+func (f *fakeApps) AddApp(app models.App) (models.App, error) {
+	app.ID = strings.TrimSpace(app.ID)
+	if app.ID == "" {
+		return models.App{}, fmt.Errorf("%w: id is required", config.ErrInvalid)
+	}
+	for _, existing := range f.apps {
+		if existing.ID == app.ID {
+			return models.App{}, config.ErrAppExists
+		}
+	}
+	f.apps = append(f.apps, app)
+	return app, nil
+}
+
+func (f *fakeApps) RemoveApp(id string) error {
+	for i, app := range f.apps {
+		if app.ID == id {
+			f.apps = append(f.apps[:i], f.apps[i+1:]...)
+			return nil
+		}
+	}
+	return config.ErrAppNotFound
+}
+
+// fakeMetadata serves fixed app metadata
+type fakeMetadata map[string]models.AppMetadata
+
+func (f fakeMetadata) Get(appID string) (models.AppMetadata, bool) {
+	metadata, ok := f[appID]
+	return metadata, ok
+}
+
+// appsRequest sends a request through a mux with the app routes registered
+func appsRequest(h *Handler, method, path, body string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/apps", h.HandleApps)
+	mux.HandleFunc("/api/apps/{id}", h.HandleApp)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return w
+}
+
+// appJSON is an app as served by /api/apps
+type appJSON struct {
+	ID        string              `json:"id"`
+	Countries []string            `json:"countries"`
+	Metadata  *models.AppMetadata `json:"metadata"`
+}
+
+func TestHandler_ListApps(t *testing.T) {
+	apps := &fakeApps{apps: []models.App{{ID: "123", Countries: []string{"us"}}, {ID: "456"}}}
+	metadata := fakeMetadata{"123": {AppID: "123", Name: "Example", Developer: "Example Inc."}}
+	handler := NewHandler(testutil.NewMockStorage(), WithApps(apps), WithMetadata(metadata))
+
+	w := appsRequest(handler, http.MethodGet, "/api/apps", "")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var response struct {
+		Apps []appJSON `json:"apps"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Apps) != 2 || response.Apps[0].ID != "123" || len(response.Apps[0].Countries) != 1 {
+		t.Fatalf("Expected apps 123 and 456, got %+v", response.Apps)
+	}
+	if response.Apps[0].Metadata == nil || response.Apps[0].Metadata.Name != "Example" {
+		t.Errorf("Expected the metadata of app 123, got %+v", response.Apps[0].Metadata)
+	}
+	if response.Apps[1].Metadata != nil {
+		t.Errorf("Expected no metadata for app 456, got %+v", response.Apps[1].Metadata)
+	}
+}
+
+func TestHandler_GetApp(t *testing.T) {
+	apps := &fakeApps{apps: []models.App{{ID: "123"}, {ID: "456"}}}
+	metadata := fakeMetadata{"456": {AppID: "456", Name: "Example", Genre: "Games"}}
+	handler := NewHandler(testutil.NewMockStorage(), WithApps(apps), WithMetadata(metadata))
+
+	w := appsRequest(handler, http.MethodGet, "/api/apps/456", "")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var response appJSON
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.ID != "456" || response.Metadata == nil || response.Metadata.Genre != "Games" {
+		t.Errorf("Expected app 456 with its metadata, got %+v", response)
+	}
+}
+
+func TestHandler_AddApp(t *testing.T) {
+	apps := &fakeApps{}
+	handler := NewHandler(testutil.NewMockStorage(), WithApps(apps))
+
+	w := appsRequest(handler, http.MethodPost, "/api/apps", `{"id": " 456 ", "countries": ["de"], "interval": "10m"}`)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(apps.apps) != 1 || apps.apps[0].ID != "456" || len(apps.apps[0].Countries) != 1 {
+		t.Errorf("Expected app 456 to be added, got %+v", apps.apps)
+	}
+
+	var response models.App
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.ID != "456" {
+		t.Errorf("Expected the stored app with its ID trimmed in the response, got %q", response.ID)
+	}
+}
+
+func TestHandler_AppErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		expected int
+	}{
+		{"invalid json", http.MethodPost, "/api/apps", `{"id":`, http.StatusBadRequest},
+		{"invalid app", http.MethodPost, "/api/apps", `{"id": ""}`, http.StatusBadRequest},
+		{"duplicate app", http.MethodPost, "/api/apps", `{"id": "123"}`, http.StatusConflict},
+		{"remove unknown app", http.MethodDelete, "/api/apps/999", "", http.StatusNotFound},
+		{"get unknown app", http.MethodGet, "/api/apps/999", "", http.StatusNotFound},
+		{"put not allowed", http.MethodPut, "/api/apps", "", http.StatusMethodNotAllowed},
+		{"post to app not allowed", http.MethodPost, "/api/apps/123", "", http.StatusMethodNotAllowed},
+	}
+
+	for _, test := range tests {
+		apps := &fakeApps{apps: []models.App{{ID: "123"}}}
+		handler := NewHandler(testutil.NewMockStorage(), WithApps(apps))
+		if w := appsRequest(handler, test.method, test.path, test.body); w.Code != test.expected {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.expected, w.Code)
+		}
+	}
+}
+
+func TestHandler_RemoveApp(t *testing.T) {
+	apps := &fakeApps{apps: []models.App{{ID: "123"}, {ID: "456"}}}
+	handler := NewHandler(testutil.NewMockStorage(), WithApps(apps))
+
+	w := appsRequest(handler, http.MethodDelete, "/api/apps/123", "")
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d", w.Code)
+	}
+	if len(apps.apps) != 1 || apps.apps[0].ID != "456" {
+		t.Errorf("Expected only app 456 to remain, got %+v", apps.apps)
+	}
+}
+
+func TestHandler_AppsWithoutRegistry(t *testing.T) {
+	handler := NewHandler(testutil.NewMockStorage())
+
+	if w := appsRequest(handler, http.MethodGet, "/api/apps", ""); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 without app management, got %d", w.Code)
+	}
+}
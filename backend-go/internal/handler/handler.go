@@ -2,29 +2,65 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"backend/internal/models"
+	"backend/internal/poller"
 	"backend/internal/storage"
 )
 
 type Handler struct {
-	storage storage.Storage
+	storage    storage.Storage
+	poller     Poller
+	apps       AppRegistry
+	metadata   MetadataRegistry
+	leadership Leadership
+	responses  bool
 }
 
-func NewHandler(storage storage.Storage) *Handler {
-	return &Handler{
+// Option customizes a Handler created by NewHandler
+type Option func(*Handler)
+
+// WithPoller enables the endpoints that trigger polls
+func WithPoller(poller Poller) Option {
+	return func(h *Handler) {
+		h.poller = poller
+	}
+}
+
+// WithResponses enables the unanswered filter, for when the developer
+// responses of iOS reviews are synced from App Store Connect
+func WithResponses() Option {
+	return func(h *Handler) {
+		h.responses = true
+	}
+}
+
+func NewHandler(storage storage.Storage, opts ...Option) *Handler {
+	h := &Handler{
 		storage: storage,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // GetRecentReviews handles GET /api/reviews
 // Query parameters:
 //   - app_id: (required) The iTunes app ID
 //   - hours: (optional) Number of hours to look back (default: 720 - 30 days)
+//   - country: (optional) Storefront country code to filter by (e.g. "us")
+//   - include_removed: (optional) Also return reviews removed from the store (default: false)
+//   - unanswered: (optional) Only return iOS reviews synced without a developer response (default: false).
+//     Requires the App Store Connect sync, see WithResponses.
 func (h *Handler) GetRecentReviews(w http.ResponseWriter, r *http.Request) {
 	// Only allow GET requests
 	if r.Method != http.MethodGet {
@@ -51,6 +87,28 @@ func (h *Handler) GetRecentReviews(w http.ResponseWriter, r *http.Request) {
 		hours = parsedHours
 	}
 
+	includeRemoved, err := parseBoolQuery(r, "include_removed")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	unanswered, err := parseBoolQuery(r, "unanswered")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if unanswered {
+		// Responses are only known for iOS reviews while the sync runs
+		if !h.responses {
+			http.Error(w, "unanswered requires app_store_connect to be configured", http.StatusBadRequest)
+			return
+		}
+		if h.isAndroidApp(appID) {
+			http.Error(w, "unanswered is only supported for iOS apps", http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Calculate time window
 	since := time.Duration(hours) * time.Hour
 
@@ -62,6 +120,15 @@ func (h *Handler) GetRecentReviews(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !includeRemoved {
+		reviews = withoutRemoved(reviews)
+	}
+
+	reviews = filterByCountry(reviews, r.URL.Query().Get("country"))
+	if unanswered {
+		reviews = withoutResponse(reviews)
+	}
+
 	// Sort by newest first (submitted_at descending)
 	sort.Slice(reviews, func(i, j int) bool {
 		return reviews[i].SubmittedAt.After(reviews[j].SubmittedAt)
@@ -77,8 +144,49 @@ func (h *Handler) GetRecentReviews(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GetReviewHistory handles GET /api/reviews/{id}/history
+// Returns every known version of the review (title, content, rating), oldest first.
+func (h *Handler) GetReviewHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.PathValue("id")
+	if id == "" {
+		http.Error(w, "review id is required", http.StatusBadRequest)
+		return
+	}
+
+	revisions, err := h.storage.GetReviewHistory(id)
+	if errors.Is(err, storage.ErrNotFound) {
+		http.Error(w, "Review not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching review history: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := map[string]any{
+		"id":        id,
+		"revisions": revisions,
+		"edited":    len(revisions) > 1,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
 // HealthCheck handles GET /api/health
-// Returns a simple health check response
+// Returns a simple health check response. With a poller the status is
+// "degraded" while feeds show schema drift, listed under schema_drift.
+// With leader election the role of the instance and the leader are included.
 func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -99,6 +207,23 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 		"total_reviews": len(allReviews),
 	}
 
+	// Feeds that stopped parsing still poll fine, so they have to be flagged here
+	if h.poller != nil {
+		var drifts []poller.DriftStatus
+		for _, status := range h.poller.Status() {
+			drifts = append(drifts, status.SchemaDrift...)
+		}
+		if len(drifts) > 0 {
+			response["status"] = "degraded"
+			response["schema_drift"] = drifts
+		}
+	}
+
+	if h.leadership != nil {
+		response["role"] = h.role()
+		response["leader"] = h.leadership.Leader().Holder
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
@@ -108,6 +233,10 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 // Query parameters:
 //   - app_id: (required) The iTunes app ID
 //   - hours: (optional) Number of hours to look back (default: 48)
+//   - country: (optional) Storefront country code to filter by (e.g. "us")
+//   - include_removed: (optional) Also count reviews removed from the store (default: false)
+//
+// The response also breaks the average down per storefront country.
 func (h *Handler) GetAverageRating(w http.ResponseWriter, r *http.Request) {
 	// Only allow GET requests
 	if r.Method != http.MethodGet {
@@ -134,6 +263,12 @@ func (h *Handler) GetAverageRating(w http.ResponseWriter, r *http.Request) {
 		hours = parsedHours
 	}
 
+	includeRemoved, err := parseBoolQuery(r, "include_removed")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Calculate time window
 	since := time.Duration(hours) * time.Hour
 
@@ -145,26 +280,36 @@ func (h *Handler) GetAverageRating(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Calculate average rating
-	var totalRating int
-	reviewCount := len(reviews)
+	if !includeRemoved {
+		reviews = withoutRemoved(reviews)
+	}
+
+	country := r.URL.Query().Get("country")
+	reviews = filterByCountry(reviews, country)
 
+	// Group reviews by storefront country
+	byCountry := make(map[string][]models.Review)
 	for _, review := range reviews {
-		totalRating += review.Rating
+		byCountry[review.Country] = append(byCountry[review.Country], review)
 	}
 
-	var averageRating float64
-	if reviewCount > 0 {
-		averageRating = float64(totalRating) / float64(reviewCount)
-		// Round to 1 decimal place
-		averageRating = float64(int(averageRating*10+0.5)) / 10
+	countries := make(map[string]any, len(byCountry))
+	for code, countryReviews := range byCountry {
+		countries[code] = map[string]any{
+			"average_rating": averageRating(countryReviews),
+			"review_count":   len(countryReviews),
+		}
 	}
 
 	response := map[string]any{
 		"app_id":         appID,
-		"average_rating": averageRating,
-		"review_count":   reviewCount,
+		"average_rating": averageRating(reviews),
+		"review_count":   len(reviews),
 		"hours":          hours,
+		"countries":      countries,
+	}
+	if country != "" {
+		response["country"] = strings.ToLower(country)
 	}
 
 	// Set response headers
@@ -176,3 +321,86 @@ func (h *Handler) GetAverageRating(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Error encoding response: %v", err)
 	}
 }
+
+// averageRating calculates the mean rating rounded to 1 decimal place,
+// returning 0 when there are no reviews
+func averageRating(reviews []models.Review) float64 {
+	if len(reviews) == 0 {
+		return 0
+	}
+
+	var totalRating int
+	for _, review := range reviews {
+		totalRating += review.Rating
+	}
+
+	average := float64(totalRating) / float64(len(reviews))
+	// Round to 1 decimal place
+	return float64(int(average*10+0.5)) / 10
+}
+
+// parseBoolQuery reads a true/false query parameter (default: false)
+func parseBoolQuery(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false", name)
+	}
+	return parsed, nil
+}
+
+// withoutRemoved drops reviews that were removed from the store
+func withoutRemoved(reviews []models.Review) []models.Review {
+	filtered := make([]models.Review, 0, len(reviews))
+	for _, review := range reviews {
+		if review.RemovedAt == nil {
+			filtered = append(filtered, review)
+		}
+	}
+	return filtered
+}
+
+// isAndroidApp reports whether appID is a tracked Google Play app
+func (h *Handler) isAndroidApp(appID string) bool {
+	if h.apps == nil {
+		return false
+	}
+	for _, app := range h.apps.Apps() {
+		if app.ID == appID {
+			return app.StorePlatform() == models.PlatformAndroid
+		}
+	}
+	return false
+}
+
+// withoutResponse keeps the reviews App Store Connect reported no developer
+// response for. Reviews it was never matched with, including all Android
+// reviews, are dropped since whether they were answered is unknown.
+func withoutResponse(reviews []models.Review) []models.Review {
+	filtered := make([]models.Review, 0, len(reviews))
+	for _, review := range reviews {
+		if review.ResponseSyncedAt != nil && review.Response == nil {
+			filtered = append(filtered, review)
+		}
+	}
+	return filtered
+}
+
+// filterByCountry keeps only reviews from the given storefront country.
+// An empty country returns the reviews unchanged.
+func filterByCountry(reviews []models.Review, country string) []models.Review {
+	if country == "" {
+		return reviews
+	}
+
+	filtered := make([]models.Review, 0, len(reviews))
+	for _, review := range reviews {
+		if strings.EqualFold(review.Country, country) {
+			filtered = append(filtered, review)
+		}
+	}
+	return filtered
+}
@@ -668,4 +668,283 @@ func TestHandler_GetAverageRating_RoundingPrecision(t *testing.T) {
 	if actualAverage < expectedAverage-0.01 || actualAverage > expectedAverage+0.01 {
 		t.Errorf("Expected average_rating approximately %.1f, got %.2f", expectedAverage, actualAverage)
 	}
-}
\ No newline at end of file
+}
+func TestHandler_GetRecentReviews_CountryFilter(t *testing.T) {
+	storage := testutil.NewMockStorage()
+	handler := NewHandler(storage)
+
+	now := time.Now()
+	reviews := []models.Review{
+		{ID: "r1", AppID: "123", Country: "us", Rating: 5, SubmittedAt: now.Add(-1 * time.Hour), FetchedAt: now},
+		{ID: "r2", AppID: "123", Country: "de", Rating: 2, SubmittedAt: now.Add(-2 * time.Hour), FetchedAt: now},
+		{ID: "r3", AppID: "123", Country: "de", Rating: 3, SubmittedAt: now.Add(-3 * time.Hour), FetchedAt: now},
+	}
+	storage.SaveReviews(reviews)
+
+	req := httptest.NewRequest("GET", "/api/reviews?app_id=123&country=DE", nil)
+	rr := httptest.NewRecorder()
+
+	handler.GetRecentReviews(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var responseReviews []models.Review
+	if err := json.NewDecoder(rr.Body).Decode(&responseReviews); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if len(responseReviews) != 2 {
+		t.Fatalf("Expected 2 reviews, got %d", len(responseReviews))
+	}
+	for _, review := range responseReviews {
+		if review.Country != "de" {
+			t.Errorf("Expected only 'de' reviews, got '%s'", review.Country)
+		}
+	}
+}
+
+func TestHandler_GetAverageRating_ByCountry(t *testing.T) {
+	storage := testutil.NewMockStorage()
+	handler := NewHandler(storage)
+
+	now := time.Now()
+	reviews := []models.Review{
+		{ID: "r1", AppID: "123", Country: "us", Rating: 5, SubmittedAt: now.Add(-1 * time.Hour), FetchedAt: now},
+		{ID: "r2", AppID: "123", Country: "de", Rating: 2, SubmittedAt: now.Add(-2 * time.Hour), FetchedAt: now},
+		{ID: "r3", AppID: "123", Country: "de", Rating: 3, SubmittedAt: now.Add(-3 * time.Hour), FetchedAt: now},
+	}
+	storage.SaveReviews(reviews)
+
+	req := httptest.NewRequest("GET", "/api/average-rating?app_id=123", nil)
+	rr := httptest.NewRecorder()
+
+	handler.GetAverageRating(rr, req)
+
+	var response struct {
+		AverageRating float64 `json:"average_rating"`
+		ReviewCount   int     `json:"review_count"`
+		Countries     map[string]struct {
+			AverageRating float64 `json:"average_rating"`
+			ReviewCount   int     `json:"review_count"`
+		} `json:"countries"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response.ReviewCount != 3 {
+		t.Errorf("Expected review_count 3, got %d", response.ReviewCount)
+	}
+	if response.AverageRating != 3.3 {
+		t.Errorf("Expected average_rating 3.3, got %v", response.AverageRating)
+	}
+	if len(response.Countries) != 2 {
+		t.Fatalf("Expected 2 countries, got %d", len(response.Countries))
+	}
+	if de := response.Countries["de"]; de.AverageRating != 2.5 || de.ReviewCount != 2 {
+		t.Errorf("Expected de average 2.5 over 2 reviews, got %+v", de)
+	}
+	if us := response.Countries["us"]; us.AverageRating != 5 || us.ReviewCount != 1 {
+		t.Errorf("Expected us average 5 over 1 review, got %+v", us)
+	}
+
+	// Filtering by country narrows the overall average
+	req = httptest.NewRequest("GET", "/api/average-rating?app_id=123&country=de", nil)
+	rr = httptest.NewRecorder()
+
+	handler.GetAverageRating(rr, req)
+
+	var filtered map[string]any
+	json.NewDecoder(rr.Body).Decode(&filtered)
+
+	if filtered["average_rating"] != 2.5 {
+		t.Errorf("Expected filtered average_rating 2.5, got %v", filtered["average_rating"])
+	}
+	if filtered["country"] != "de" {
+		t.Errorf("Expected country 'de', got %v", filtered["country"])
+	}
+}
+
+func TestHandler_GetReviewHistory_Success(t *testing.T) {
+	storage := testutil.NewMockStorage()
+	handler := NewHandler(storage)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	storage.SaveReviews([]models.Review{{ID: "r1", AppID: "123", Content: "Now great", Rating: 4, FetchedAt: now}})
+	storage.SetReviewHistory("r1", []models.ReviewRevision{
+		{Content: "Crashes", Rating: 1, RecordedAt: now.Add(-time.Hour)},
+		{Content: "Now great", Rating: 4, RecordedAt: now},
+	})
+
+	req := httptest.NewRequest("GET", "/api/reviews/r1/history", nil)
+	req.SetPathValue("id", "r1")
+	rr := httptest.NewRecorder()
+
+	handler.GetReviewHistory(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var response struct {
+		ID        string                  `json:"id"`
+		Revisions []models.ReviewRevision `json:"revisions"`
+		Edited    bool                    `json:"edited"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response.ID != "r1" || !response.Edited {
+		t.Errorf("Expected edited review r1, got id %q edited %v", response.ID, response.Edited)
+	}
+	if len(response.Revisions) != 2 {
+		t.Fatalf("Expected 2 revisions, got %d", len(response.Revisions))
+	}
+	if response.Revisions[0].Rating != 1 || response.Revisions[1].Rating != 4 {
+		t.Errorf("Expected revisions oldest first, got %+v", response.Revisions)
+	}
+}
+
+func TestHandler_GetReviewHistory_NotFound(t *testing.T) {
+	handler := NewHandler(testutil.NewMockStorage())
+
+	req := httptest.NewRequest("GET", "/api/reviews/missing/history", nil)
+	req.SetPathValue("id", "missing")
+	rr := httptest.NewRecorder()
+
+	handler.GetReviewHistory(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, rr.Code)
+	}
+}
+
+func TestHandler_RemovedReviewsExcludedByDefault(t *testing.T) {
+	storage := testutil.NewMockStorage()
+	handler := NewHandler(storage)
+
+	now := time.Now()
+	removedAt := now.Add(-30 * time.Minute)
+	storage.SaveReviews([]models.Review{
+		{ID: "r1", AppID: "123", Rating: 5, SubmittedAt: now.Add(-1 * time.Hour)},
+		{ID: "r2", AppID: "123", Rating: 1, SubmittedAt: now.Add(-2 * time.Hour), RemovedAt: &removedAt},
+	})
+
+	tests := []struct {
+		query         string
+		expectedCount int
+		expectedAvg   float64
+	}{
+		{"app_id=123", 1, 5},
+		{"app_id=123&include_removed=false", 1, 5},
+		{"app_id=123&include_removed=true", 2, 3},
+	}
+
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		handler.GetRecentReviews(rr, httptest.NewRequest("GET", "/api/reviews?"+test.query, nil))
+
+		var reviews []models.Review
+		json.NewDecoder(rr.Body).Decode(&reviews)
+		if len(reviews) != test.expectedCount {
+			t.Errorf("%s: expected %d reviews, got %d", test.query, test.expectedCount, len(reviews))
+		}
+
+		rr = httptest.NewRecorder()
+		handler.GetAverageRating(rr, httptest.NewRequest("GET", "/api/average-rating?"+test.query, nil))
+
+		var response map[string]any
+		json.NewDecoder(rr.Body).Decode(&response)
+		if response["average_rating"] != test.expectedAvg {
+			t.Errorf("%s: expected average %v, got %v", test.query, test.expectedAvg, response["average_rating"])
+		}
+	}
+}
+
+func TestHandler_InvalidIncludeRemoved(t *testing.T) {
+	handler := NewHandler(testutil.NewMockStorage())
+
+	rr := httptest.NewRecorder()
+	handler.GetRecentReviews(rr, httptest.NewRequest("GET", "/api/reviews?app_id=123&include_removed=maybe", nil))
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestHandler_GetRecentReviews_Unanswered(t *testing.T) {
+	storage := testutil.NewMockStorage()
+	handler := NewHandler(storage, WithResponses())
+
+	now := time.Now()
+	storage.SaveReviews([]models.Review{
+		{ID: "answered", AppID: "123", SubmittedAt: now.Add(-1 * time.Hour), Response: &models.DeveloperResponse{
+			Body: "Thanks!", State: models.ResponseStatePublished, LastModifiedAt: now,
+		}, ResponseSyncedAt: &now},
+		{ID: "pending", AppID: "123", SubmittedAt: now.Add(-2 * time.Hour), Response: &models.DeveloperResponse{
+			Body: "We are on it", State: models.ResponseStatePendingPublish, LastModifiedAt: now,
+		}, ResponseSyncedAt: &now},
+		{ID: "unanswered", AppID: "123", SubmittedAt: now.Add(-3 * time.Hour), ResponseSyncedAt: &now},
+		{ID: "unsynced", AppID: "123", SubmittedAt: now.Add(-4 * time.Hour)},
+	})
+
+	rr := httptest.NewRecorder()
+	handler.GetRecentReviews(rr, httptest.NewRequest("GET", "/api/reviews?app_id=123&unanswered=true", nil))
+
+	var reviews []models.Review
+	json.NewDecoder(rr.Body).Decode(&reviews)
+	if len(reviews) != 1 || reviews[0].ID != "unanswered" {
+		t.Errorf("Expected only the review synced without a response, got %+v", reviews)
+	}
+
+	// Without the filter the responses are included
+	rr = httptest.NewRecorder()
+	handler.GetRecentReviews(rr, httptest.NewRequest("GET", "/api/reviews?app_id=123", nil))
+	reviews = nil
+	json.NewDecoder(rr.Body).Decode(&reviews)
+	if len(reviews) != 4 || reviews[0].Response == nil || reviews[0].Response.Body != "Thanks!" {
+		t.Errorf("Expected all reviews with their responses, got %+v", reviews)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.GetRecentReviews(rr, httptest.NewRequest("GET", "/api/reviews?app_id=123&unanswered=maybe", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an invalid unanswered value, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestHandler_GetRecentReviews_UnansweredOnlyForSyncedIOSReviews(t *testing.T) {
+	storage := testutil.NewMockStorage()
+	now := time.Now()
+	storage.SaveReviews([]models.Review{
+		{ID: "ios", AppID: "123", Platform: models.PlatformIOS, SubmittedAt: now.Add(-1 * time.Hour), ResponseSyncedAt: &now},
+		{ID: "android", AppID: "com.example.app", Platform: models.PlatformAndroid, SubmittedAt: now.Add(-1 * time.Hour)},
+	})
+	apps := &fakeApps{apps: []models.App{{ID: "123"}, {ID: "com.example.app", Platform: models.PlatformAndroid}}}
+
+	// Without the App Store Connect sync no review is known to be answered
+	rr := httptest.NewRecorder()
+	NewHandler(storage, WithApps(apps)).GetRecentReviews(rr, httptest.NewRequest("GET", "/api/reviews?app_id=123&unanswered=true", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d without the sync, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	// Google Play replies are not synced
+	handler := NewHandler(storage, WithApps(apps), WithResponses())
+	rr = httptest.NewRecorder()
+	handler.GetRecentReviews(rr, httptest.NewRequest("GET", "/api/reviews?app_id=com.example.app&unanswered=true", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an Android app, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.GetRecentReviews(rr, httptest.NewRequest("GET", "/api/reviews?app_id=123&unanswered=true", nil))
+	var reviews []models.Review
+	json.NewDecoder(rr.Body).Decode(&reviews)
+	if rr.Code != http.StatusOK || len(reviews) != 1 || reviews[0].ID != "ios" {
+		t.Errorf("Expected the unanswered iOS review, got %d %+v", rr.Code, reviews)
+	}
+}
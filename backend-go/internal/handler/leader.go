@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+
+	"backend/internal/leader"
+)
+
+// Leadership tells whether this instance leads the instances sharing the data
+type Leadership interface {
+	IsLeader() bool
+	Leader() leader.Lease
+}
+
+// WithLeadership rejects polls and app changes while another instance leads,
+// since only the leader writes
+func WithLeadership(leadership Leadership) Option {
+	return func(h *Handler) {
+		h.leadership = leadership
+	}
+}
+
+// rejectOnFollower answers a request that changes data with 503 when this
+// instance is a follower and reports whether it did
+func (h *Handler) rejectOnFollower(w http.ResponseWriter) bool {
+	if h.leadership == nil || h.leadership.IsLeader() {
+		return false
+	}
+
+	if holder := h.leadership.Leader().Holder; holder != "" {
+		http.Error(w, "Not the leader, changes are handled by "+holder, http.StatusServiceUnavailable)
+	} else {
+		http.Error(w, "Not the leader, no leader elected yet", http.StatusServiceUnavailable)
+	}
+	return true
+}
+
+// role describes this instance for the health check
+func (h *Handler) role() string {
+	if h.leadership.IsLeader() {
+		return "leader"
+	}
+	return "follower"
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend/internal/leader"
+	"backend/internal/models"
+	"backend/internal/poller"
+	"backend/internal/testutil"
+)
+
+// fakeLeadership is a fixed Leadership
+type fakeLeadership struct {
+	leading bool
+	holder  string
+}
+
+func (f *fakeLeadership) IsLeader() bool {
+	return f.leading
+}
+
+func (f *fakeLeadership) Leader() leader.Lease {
+	return leader.Lease{Holder: f.holder}
+}
+
+func TestHandler_FollowerRejectsWrites(t *testing.T) {
+	apps := &fakeApps{apps: []models.App{{ID: "123"}}}
+	fake := &fakePoller{results: map[string]poller.PollResult{"123": {AppID: "123"}}}
+	handler := NewHandler(testutil.NewMockStorage(),
+		WithApps(apps),
+		WithPoller(fake),
+		WithLeadership(&fakeLeadership{holder: "replica-1"}),
+	)
+
+	writes := []*httptest.ResponseRecorder{
+		appsRequest(handler, http.MethodPost, "/api/apps", `{"id": "456"}`),
+		appsRequest(handler, http.MethodDelete, "/api/apps/123", ""),
+		pollRequest(handler, http.MethodPost, "/api/apps/123/poll"),
+		pollRequest(handler, http.MethodPost, "/api/poll"),
+	}
+	for i, w := range writes {
+		if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "replica-1") {
+			t.Errorf("Request %d: expected 503 naming the leader, got %d: %s", i, w.Code, w.Body.String())
+		}
+	}
+	if len(apps.apps) != 1 || len(fake.polled) != 0 {
+		t.Errorf("Expected a follower to change nothing, got apps %+v and polls %v", apps.apps, fake.polled)
+	}
+
+	// Reads are served
+	if w := appsRequest(handler, http.MethodGet, "/api/apps/123", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected a follower to serve reads, got %d", w.Code)
+	}
+}
+
+func TestHandler_LeaderAcceptsWrites(t *testing.T) {
+	apps := &fakeApps{}
+	handler := NewHandler(testutil.NewMockStorage(),
+		WithApps(apps),
+		WithLeadership(&fakeLeadership{leading: true, holder: "replica-1"}),
+	)
+
+	if w := appsRequest(handler, http.MethodPost, "/api/apps", `{"id": "456"}`); w.Code != http.StatusCreated {
+		t.Errorf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHandler_HealthCheck_Role(t *testing.T) {
+	leadership := &fakeLeadership{holder: "replica-1"}
+	handler := NewHandler(testutil.NewMockStorage(), WithLeadership(leadership))
+
+	for _, leading := range []bool{false, true} {
+		leadership.leading = leading
+		w := httptest.NewRecorder()
+		handler.HealthCheck(w, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+
+		var response struct {
+			Role   string `json:"role"`
+			Leader string `json:"leader"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		expected := map[bool]string{true: "leader", false: "follower"}[leading]
+		if response.Role != expected || response.Leader != "replica-1" {
+			t.Errorf("Expected role %q with leader replica-1, got %+v", expected, response)
+		}
+	}
+}
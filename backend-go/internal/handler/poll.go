@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"backend/internal/poller"
+)
+
+// pollTimeout is how long a poll request may take before its response is cut
+// off, longer than the server's write timeout since polls wait for the store
+const pollTimeout = 2 * time.Minute
+
+// Poller triggers polls outside the regular schedule and reports how polling goes
+type Poller interface {
+	PollApp(appID string) (poller.PollResult, error)
+	PollAll() ([]poller.PollResult, error)
+	Status() []poller.AppStatus
+}
+
+// PollApp handles POST /api/apps/{id}/poll
+// Polls the app right away, joining a poll of the app that is already running,
+// and returns how many new reviews were found.
+func (h *Handler) PollApp(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.poller == nil {
+		http.Error(w, "Polling is not available", http.StatusServiceUnavailable)
+		return
+	}
+	if h.rejectOnFollower(w) {
+		return
+	}
+
+	appID := r.PathValue("id")
+	if appID == "" {
+		http.Error(w, "app id is required", http.StatusBadRequest)
+		return
+	}
+
+	extendWriteDeadline(w)
+	result, err := h.poller.PollApp(appID)
+	if err != nil {
+		writePollError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	switch {
+	case result.Skipped, result.Cancelled:
+		status = http.StatusServiceUnavailable // Circuit breaker open or poller stopping
+	case result.Error != "":
+		status = http.StatusBadGateway
+	}
+	writeJSON(w, status, result)
+}
+
+// PollAll handles POST /api/poll
+// Polls every app right away and returns the result per app.
+func (h *Handler) PollAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.poller == nil {
+		http.Error(w, "Polling is not available", http.StatusServiceUnavailable)
+		return
+	}
+	if h.rejectOnFollower(w) {
+		return
+	}
+
+	extendWriteDeadline(w)
+	results, err := h.poller.PollAll()
+	if err != nil {
+		writePollError(w, err)
+		return
+	}
+
+	newReviews := 0
+	for _, result := range results {
+		newReviews += result.New
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"new":  newReviews,
+		"apps": results,
+	})
+}
+
+// GetPollStatus handles GET /api/poll-status
+// Returns per app when it was last polled, whether that worked and what it found,
+// so clients can tell how fresh the stored reviews are.
+func (h *Handler) GetPollStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.poller == nil {
+		http.Error(w, "Polling is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"timestamp": time.Now().Format(time.RFC3339),
+		"apps":      h.poller.Status(),
+	})
+}
+
+// extendWriteDeadline gives a poll request longer than the server's write timeout
+func extendWriteDeadline(w http.ResponseWriter) {
+	// Not every ResponseWriter supports deadlines (e.g. in tests), polling works regardless
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(pollTimeout))
+}
+
+// writePollError maps an error of an on-demand poll to a response
+func writePollError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, poller.ErrUnknownApp):
+		http.Error(w, "App not found", http.StatusNotFound)
+	case errors.Is(err, poller.ErrNotRunning):
+		http.Error(w, "Poller is not running", http.StatusServiceUnavailable)
+	default:
+		log.Printf("Error polling: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// writeJSON writes value as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
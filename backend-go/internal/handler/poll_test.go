@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/poller"
+	"backend/internal/testutil"
+)
+
+// fakePoller returns canned poll results
+type fakePoller struct {
+	results  map[string]poller.PollResult
+	statuses []poller.AppStatus
+	err      error
+	polled   []string
+}
+
+func (f *fakePoller) Status() []poller.AppStatus {
+	return f.statuses
+}
+
+func (f *fakePoller) PollApp(appID string) (poller.PollResult, error) {
+	f.polled = append(f.polled, appID)
+	if f.err != nil {
+		return poller.PollResult{}, f.err
+	}
+	result, ok := f.results[appID]
+	if !ok {
+		return poller.PollResult{}, fmt.Errorf("%w: %s", poller.ErrUnknownApp, appID)
+	}
+	return result, nil
+}
+
+func (f *fakePoller) PollAll() ([]poller.PollResult, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	results := make([]poller.PollResult, 0, len(f.results))
+	for _, id := range []string{"123", "456"} {
+		if result, ok := f.results[id]; ok {
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// pollRequest sends a request through a mux with the poll routes registered
+func pollRequest(h *Handler, method, path string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/apps/{id}/poll", h.PollApp)
+	mux.HandleFunc("/api/poll", h.PollAll)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestHandler_PollApp_Success(t *testing.T) {
+	fake := &fakePoller{results: map[string]poller.PollResult{
+		"123": {AppID: "123", Fetched: 50, New: 3},
+	}}
+	handler := NewHandler(testutil.NewMockStorage(), WithPoller(fake))
+
+	w := pollRequest(handler, http.MethodPost, "/api/apps/123/poll")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var result poller.PollResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if result.AppID != "123" || result.New != 3 || result.Fetched != 50 {
+		t.Errorf("Unexpected poll result: %+v", result)
+	}
+	if len(fake.polled) != 1 || fake.polled[0] != "123" {
+		t.Errorf("Expected app 123 to be polled, got %v", fake.polled)
+	}
+}
+
+func TestHandler_PollApp_Statuses(t *testing.T) {
+	tests := []struct {
+		name     string
+		poller   *fakePoller
+		method   string
+		path     string
+		expected int
+	}{
+		{"unknown app", &fakePoller{}, http.MethodPost, "/api/apps/999/poll", http.StatusNotFound},
+		{"not running", &fakePoller{err: poller.ErrNotRunning}, http.MethodPost, "/api/apps/123/poll", http.StatusServiceUnavailable},
+		{"breaker open", &fakePoller{results: map[string]poller.PollResult{"123": {AppID: "123", Skipped: true}}},
+			http.MethodPost, "/api/apps/123/poll", http.StatusServiceUnavailable},
+		{"poll failed", &fakePoller{results: map[string]poller.PollResult{"123": {AppID: "123", Error: "HTTP 500"}}},
+			http.MethodPost, "/api/apps/123/poll", http.StatusBadGateway},
+		{"get not allowed", &fakePoller{}, http.MethodGet, "/api/apps/123/poll", http.StatusMethodNotAllowed},
+		{"get all not allowed", &fakePoller{}, http.MethodGet, "/api/poll", http.StatusMethodNotAllowed},
+	}
+
+	for _, test := range tests {
+		handler := NewHandler(testutil.NewMockStorage(), WithPoller(test.poller))
+		if w := pollRequest(handler, test.method, test.path); w.Code != test.expected {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.expected, w.Code)
+		}
+	}
+}
+
+func TestHandler_PollWithoutPoller(t *testing.T) {
+	handler := NewHandler(testutil.NewMockStorage())
+
+	for _, path := range []string{"/api/apps/123/poll", "/api/poll"} {
+		if w := pollRequest(handler, http.MethodPost, path); w.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s: expected status 503 without a poller, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestHandler_PollAll(t *testing.T) {
+	fake := &fakePoller{results: map[string]poller.PollResult{
+		"123": {AppID: "123", Fetched: 50, New: 3},
+		"456": {AppID: "456", Fetched: 10, New: 1},
+	}}
+	handler := NewHandler(testutil.NewMockStorage(), WithPoller(fake))
+
+	w := pollRequest(handler, http.MethodPost, "/api/poll")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		New  int                 `json:"new"`
+		Apps []poller.PollResult `json:"apps"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.New != 4 || len(response.Apps) != 2 {
+		t.Errorf("Expected 4 new reviews across 2 apps, got %+v", response)
+	}
+}
+
+func TestHandler_GetPollStatus(t *testing.T) {
+	lastSuccess := time.Date(2025, 9, 28, 10, 0, 0, 0, time.UTC)
+	fake := &fakePoller{statuses: []poller.AppStatus{
+		{AppID: "123", LastAttempt: &lastSuccess, LastSuccess: &lastSuccess, ReviewsFetched: 50, ReviewsNew: 3},
+		{AppID: "456", LastError: "HTTP 503", ConsecutiveFailures: 2},
+	}}
+	handler := NewHandler(testutil.NewMockStorage(), WithPoller(fake))
+
+	w := httptest.NewRecorder()
+	handler.GetPollStatus(w, httptest.NewRequest(http.MethodGet, "/api/poll-status", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var response struct {
+		Apps []poller.AppStatus `json:"apps"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Apps) != 2 {
+		t.Fatalf("Expected status of 2 apps, got %+v", response.Apps)
+	}
+	if first := response.Apps[0]; first.LastSuccess == nil || !first.LastSuccess.Equal(lastSuccess) || first.ReviewsNew != 3 {
+		t.Errorf("Unexpected status of app 123: %+v", first)
+	}
+	if second := response.Apps[1]; second.LastSuccess != nil || second.ConsecutiveFailures != 2 || second.LastError != "HTTP 503" {
+		t.Errorf("Unexpected status of app 456: %+v", second)
+	}
+}
+
+func TestHandler_GetPollStatus_Errors(t *testing.T) {
+	w := httptest.NewRecorder()
+	NewHandler(testutil.NewMockStorage()).GetPollStatus(w, httptest.NewRequest(http.MethodGet, "/api/poll-status", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 without a poller, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	NewHandler(testutil.NewMockStorage(), WithPoller(&fakePoller{})).
+		GetPollStatus(w, httptest.NewRequest(http.MethodPost, "/api/poll-status", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for POST, got %d", w.Code)
+	}
+}
+
+func TestHandler_HealthCheck_SchemaDrift(t *testing.T) {
+	since := time.Date(2025, 9, 28, 12, 0, 0, 0, time.UTC)
+	fake := &fakePoller{statuses: []poller.AppStatus{
+		{AppID: "123"},
+		{AppID: "456", SchemaDrift: []poller.DriftStatus{
+			{AppID: "456", Country: "us", Since: since, Reason: "only 0 of 50 entries parsed"},
+		}},
+	}}
+	handler := NewHandler(testutil.NewMockStorage(), WithPoller(fake))
+
+	w := httptest.NewRecorder()
+	handler.HealthCheck(w, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var response struct {
+		Status      string               `json:"status"`
+		SchemaDrift []poller.DriftStatus `json:"schema_drift"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Status != "degraded" {
+		t.Errorf("Expected status 'degraded', got %q", response.Status)
+	}
+	if len(response.SchemaDrift) != 1 || response.SchemaDrift[0].AppID != "456" || !response.SchemaDrift[0].Since.Equal(since) {
+		t.Errorf("Expected the drift of app 456, got %+v", response.SchemaDrift)
+	}
+
+	// Healthy again once the drift is gone
+	fake.statuses = []poller.AppStatus{{AppID: "123"}, {AppID: "456"}}
+	w = httptest.NewRecorder()
+	handler.HealthCheck(w, httptest.NewRequest(http.MethodGet, "/api/health", nil))
+	if !strings.Contains(w.Body.String(), `"status":"healthy"`) || strings.Contains(w.Body.String(), "schema_drift") {
+		t.Errorf("Expected a healthy response without drift, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,113 @@
+package httpclient
+
+import (
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNew_Defaults(t *testing.T) {
+	client, err := New(DefaultConfig())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if client.Timeout != 30*time.Second {
+		t.Errorf("Expected timeout 30s, got %v", client.Timeout)
+	}
+	transport := client.Transport.(*http.Transport)
+	if transport.TLSHandshakeTimeout != 10*time.Second || transport.MaxIdleConns != 100 || transport.IdleConnTimeout != 90*time.Second {
+		t.Errorf("Unexpected transport settings %+v", transport)
+	}
+}
+
+func TestNew_CAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	// The test server's certificate is self-signed, so it is unknown to the system roots
+	client, _ := New(DefaultConfig())
+	if _, err := client.Get(server.URL); err == nil {
+		t.Fatal("Expected a certificate error without the CA file, got nil")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	os.WriteFile(caFile, certificate, 0644)
+
+	config := DefaultConfig()
+	config.CAFile = caFile
+	client, err := New(config)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected the CA file to be trusted, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNew_Proxy(t *testing.T) {
+	var proxied string
+	var credentials string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A proxy receives the absolute URL of plain HTTP requests
+		proxied = r.URL.String()
+		credentials = r.Header.Get("Proxy-Authorization")
+		w.Write([]byte("from proxy"))
+	}))
+	defer proxy.Close()
+
+	config := DefaultConfig()
+	config.ProxyURL = "http://user:secret@" + proxy.Listener.Addr().String()
+	client, err := New(config)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	resp, err := client.Get("http://itunes.example.com/lookup?id=123")
+	if err != nil {
+		t.Fatalf("Request through the proxy failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if string(body) != "from proxy" || proxied != "http://itunes.example.com/lookup?id=123" {
+		t.Errorf("Expected the request to go through the proxy, got %q for %q", body, proxied)
+	}
+	if credentials != "Basic "+base64.StdEncoding.EncodeToString([]byte("user:secret")) {
+		t.Errorf("Expected proxy credentials from the URL, got %q", credentials)
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	emptyCA := filepath.Join(t.TempDir(), "empty.pem")
+	os.WriteFile(emptyCA, []byte("not a certificate"), 0644)
+
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{"proxy without host", func(c *Config) { c.ProxyURL = "http://" }},
+		{"proxy with unsupported scheme", func(c *Config) { c.ProxyURL = "ftp://proxy:21" }},
+		{"missing CA file", func(c *Config) { c.CAFile = filepath.Join(t.TempDir(), "missing.pem") }},
+		{"CA file without certificates", func(c *Config) { c.CAFile = emptyCA }},
+	}
+
+	for _, test := range tests {
+		config := DefaultConfig()
+		test.modify(&config)
+		if _, err := New(config); err == nil {
+			t.Errorf("%s: expected error, got nil", test.name)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"backend/internal/storage"
+)
+
+// Recording is a raw response saved by Record
+type Recording struct {
+	URL        string      `json:"url"`
+	Status     int         `json:"status"`
+	Header     http.Header `json:"headers"`
+	Body       string      `json:"body"`
+	RecordedAt time.Time   `json:"recorded_at"`
+	Sequence   string      `json:"sequence,omitempty"` // Name passed to WithSequence, empty for single requests
+	Run        string      `json:"run,omitempty"`      // Identifies the run of Sequence the response belongs to
+}
+
+// sequenceKey is the context key of WithSequence
+type sequenceKey struct{}
+
+// sequence is a run of related requests, see WithSequence
+type sequence struct {
+	name string
+	run  string
+}
+
+var sequenceRuns atomic.Int64
+
+// WithSequence marks the requests made with the returned context as one run
+// of name, e.g. the pages fetched by one poll of a feed. Replay serves such a
+// run the responses recorded in a single run of name, so pages of different
+// polls are never mixed.
+func WithSequence(ctx context.Context, name string) context.Context {
+	run := fmt.Sprintf("%d-%d", time.Now().UnixNano(), sequenceRuns.Add(1))
+	return context.WithValue(ctx, sequenceKey{}, sequence{name: name, run: run})
+}
+
+// sequenceOf returns the run req belongs to. A request outside of
+// WithSequence is a run of its own, named after its URL.
+func sequenceOf(req *http.Request) sequence {
+	if seq, ok := req.Context().Value(sequenceKey{}).(sequence); ok {
+		return seq
+	}
+	return sequence{name: req.URL.String()}
+}
+
+// Record saves every GET response of client to a file in dir, so problems
+// can be reproduced later with Replay. POSTs are not recorded, the OAuth
+// token exchange of Google Play would leak credentials. Requests are sent
+// without conditional headers, so every recording has a body that a replay
+// without validators can use. The transport of client is wrapped in place.
+func Record(client *http.Client, dir string, logger *log.Logger) {
+	client.Transport = &recordingTransport{
+		next:   transportOrDefault(client.Transport),
+		dir:    dir,
+		logger: logger,
+	}
+}
+
+// Replay answers the requests of client from the recordings in dir instead
+// of the network. Each run of a sequence (see WithSequence) gets the
+// responses of the next recorded run, the last one repeating once all were
+// served; within a run the responses to a URL are served in recorded order.
+// Recorded 304s are only served to conditional requests. URLs without a
+// recording get a 404, except OAuth token requests, which get a made-up token
+// so Google Play apps can be replayed too. If the recordings cannot be read,
+// client replays without them and the error is returned.
+func Replay(client *http.Client, dir string, logger *log.Logger) error {
+	transport, err := loadReplayTransport(dir, logger)
+	client.Transport = transport
+	return err
+}
+
+func transportOrDefault(transport http.RoundTripper) http.RoundTripper {
+	if transport == nil {
+		return http.DefaultTransport
+	}
+	return transport
+}
+
+// recordingTransport passes requests on and saves the responses of GETs
+type recordingTransport struct {
+	next   http.RoundTripper
+	dir    string
+	logger *log.Logger
+	seq    atomic.Int64 // Keeps file names unique within the same nanosecond
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet && isConditional(req) {
+		req = req.Clone(req.Context())
+		req.Header.Del("If-None-Match")
+		req.Header.Del("If-Modified-Since")
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || req.Method != http.MethodGet {
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	recording := Recording{
+		URL:        req.URL.String(),
+		Status:     resp.StatusCode,
+		Header:     resp.Header,
+		Body:       string(body),
+		RecordedAt: time.Now().UTC(),
+	}
+	if seq, ok := req.Context().Value(sequenceKey{}).(sequence); ok {
+		recording.Sequence = seq.name
+		recording.Run = seq.run
+	}
+	if err := t.save(recording); err != nil {
+		// Recording is a debugging aid, the request goes on without it
+		t.logger.Printf("Warning: failed to record response of %s: %v", recording.URL, err)
+	}
+
+	return resp, nil
+}
+
+// save writes a recording to a file named after its time, so the files
+// sort in the order they were recorded
+func (t *recordingTransport) save(recording Recording) error {
+	data, err := json.MarshalIndent(recording, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal recording: %w", err)
+	}
+
+	name := fmt.Sprintf("%s-%06d.json", recording.RecordedAt.Format("20060102T150405.000000000"), t.seq.Add(1))
+	return storage.WriteFileAtomic(filepath.Join(t.dir, name), data)
+}
+
+// LoadRecordings reads the recordings saved in dir, oldest first
+func LoadRecordings(dir string) ([]Recording, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recordings: %w", err)
+	}
+	sort.Strings(paths)
+
+	recordings := make([]Recording, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return recordings, fmt.Errorf("failed to read recording: %w", err)
+		}
+
+		var recording Recording
+		if err := json.Unmarshal(data, &recording); err != nil {
+			return recordings, fmt.Errorf("failed to unmarshal recording %s: %w", filepath.Base(path), err)
+		}
+		recordings = append(recordings, recording)
+	}
+	return recordings, nil
+}
+
+// replayRun holds the responses recorded in one run of a sequence, per URL
+// in recorded order
+type replayRun map[string][]Recording
+
+// liveRun is a run of a sequence being replayed
+type liveRun struct {
+	run      string
+	recorded replayRun
+	served   map[string]int // Responses served per URL
+}
+
+// replayTransport answers requests with recorded responses
+type replayTransport struct {
+	mu     sync.Mutex
+	runs   map[string][]replayRun // Per sequence name, oldest first
+	next   map[string]int         // Index of the recorded run the next run of a sequence gets
+	live   map[string]*liveRun    // Latest run per sequence name
+	logger *log.Logger
+}
+
+// loadReplayTransport reads all recordings in dir. On error a transport
+// without recordings is returned too.
+func loadReplayTransport(dir string, logger *log.Logger) (*replayTransport, error) {
+	transport := &replayTransport{
+		runs:   make(map[string][]replayRun),
+		next:   make(map[string]int),
+		live:   make(map[string]*liveRun),
+		logger: logger,
+	}
+
+	recordings, err := LoadRecordings(dir)
+	if err != nil {
+		return transport, err
+	}
+	transport.add(recordings)
+
+	logger.Printf("Replaying %d recorded responses of %d sequences from %s", len(recordings), len(transport.runs), dir)
+	return transport, nil
+}
+
+// add groups recordings by sequence and run. Recordings without a sequence,
+// including those saved before sequences were recorded, are runs of their own.
+func (t *replayTransport) add(recordings []Recording) {
+	type runKey struct{ sequence, run string }
+	runs := make(map[runKey]replayRun)
+
+	for _, recording := range recordings {
+		key := runKey{sequence: recording.Sequence, run: recording.Run}
+		if key.sequence == "" {
+			key = runKey{sequence: recording.URL}
+		}
+
+		run, ok := runs[key]
+		if !ok || key.run == "" {
+			run = make(replayRun)
+			runs[key] = run
+			t.runs[key.sequence] = append(t.runs[key.sequence], run)
+		}
+		run[recording.URL] = append(run[recording.URL], recording)
+	}
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	if req.Method == http.MethodPost && req.Header.Get("Content-Type") == "application/x-www-form-urlencoded" {
+		// Token exchanges are never recorded, any token will do for the recorded GETs
+		return replayResponse(req, http.StatusOK, http.Header{"Content-Type": {"application/json"}},
+			`{"access_token": "replay", "token_type": "Bearer", "expires_in": 3600}`), nil
+	}
+
+	url := req.URL.String()
+	seq := sequenceOf(req)
+
+	t.mu.Lock()
+	var recordings []Recording
+	var served int
+	if live := t.liveRun(seq); live != nil {
+		recordings = live.recorded[url]
+		if !isConditional(req) {
+			recordings = withBody(recordings)
+		}
+		served = live.served[url]
+		live.served[url]++
+	}
+	t.mu.Unlock()
+
+	if len(recordings) == 0 {
+		t.logger.Printf("No recording for %s %s", req.Method, url)
+		return replayResponse(req, http.StatusNotFound, make(http.Header), ""), nil
+	}
+	recording := recordings[min(served, len(recordings)-1)]
+	return replayResponse(req, recording.Status, recording.Header.Clone(), recording.Body), nil
+}
+
+// liveRun returns the run seq is replayed from, moving on to the next
+// recorded run of its sequence when seq is a new run. It returns nil if the
+// sequence was never recorded.
+func (t *replayTransport) liveRun(seq sequence) *liveRun {
+	if live := t.live[seq.name]; live != nil && seq.run != "" && live.run == seq.run {
+		return live
+	}
+
+	runs := t.runs[seq.name]
+	if len(runs) == 0 {
+		return nil
+	}
+	live := &liveRun{
+		run:      seq.run,
+		recorded: runs[min(t.next[seq.name], len(runs)-1)],
+		served:   make(map[string]int),
+	}
+	t.next[seq.name]++
+	t.live[seq.name] = live
+	return live
+}
+
+// isConditional reports whether req carries validators
+func isConditional(req *http.Request) bool {
+	return req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != ""
+}
+
+// withBody drops the 304s from recordings, which a request without
+// validators could not have received
+func withBody(recordings []Recording) []Recording {
+	filtered := make([]Recording, 0, len(recordings))
+	for _, recording := range recordings {
+		if recording.Status != http.StatusNotModified {
+			filtered = append(filtered, recording)
+		}
+	}
+	return filtered
+}
+
+func replayResponse(req *http.Request, status int, header http.Header, body string) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
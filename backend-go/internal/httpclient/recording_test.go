@@ -0,0 +1,188 @@
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// getBody performs a GET with client and returns the status and body
+func getBody(t *testing.T, client *http.Client, ctx context.Context, url string) (int, string) {
+	t.Helper()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprintf(w, "%s %d", r.URL.Path, requests.Add(1))
+	}))
+
+	dir := t.TempDir()
+	recorder := &http.Client{}
+	Record(recorder, dir, log.New(io.Discard, "", 0))
+
+	// Two runs of a paged sequence, then a single request
+	first := WithSequence(context.Background(), "feed")
+	getBody(t, recorder, first, server.URL+"/page1")
+	getBody(t, recorder, first, server.URL+"/page2")
+	getBody(t, recorder, WithSequence(context.Background(), "feed"), server.URL+"/page1")
+	getBody(t, recorder, context.Background(), server.URL+"/lookup")
+	server.Close()
+
+	recordings, err := LoadRecordings(dir)
+	if err != nil {
+		t.Fatalf("LoadRecordings failed: %v", err)
+	}
+	if len(recordings) != 4 {
+		t.Fatalf("Expected 4 recordings, got %d", len(recordings))
+	}
+	if got := recordings[0]; got.URL != server.URL+"/page1" || got.Status != http.StatusOK ||
+		got.Header.Get("ETag") != `"v1"` || got.RecordedAt.IsZero() || got.Sequence != "feed" || got.Run == "" {
+		t.Errorf("Expected URL, status, headers, time and sequence to be recorded, got %+v", got)
+	}
+	if recordings[0].Run != recordings[1].Run || recordings[0].Run == recordings[2].Run {
+		t.Errorf("Expected the runs of the sequence to be told apart, got %+v", recordings)
+	}
+	if recordings[3].Sequence != "" {
+		t.Errorf("Expected no sequence for a single request, got %+v", recordings[3])
+	}
+
+	replayer := &http.Client{}
+	if err := Replay(replayer, dir, log.New(io.Discard, "", 0)); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+
+	// The second run only recorded page 1, so it gets no page 2 from the first
+	second := WithSequence(context.Background(), "feed")
+	tests := []struct {
+		ctx    context.Context
+		path   string
+		status int
+		body   string
+	}{
+		{first, "/page1", http.StatusOK, "/page1 1"},
+		{first, "/page2", http.StatusOK, "/page2 2"},
+		{second, "/page1", http.StatusOK, "/page1 3"},
+		{second, "/page2", http.StatusNotFound, ""},
+		{context.Background(), "/lookup", http.StatusOK, "/lookup 4"},
+		{context.Background(), "/unknown", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		status, body := getBody(t, replayer, tt.ctx, server.URL+tt.path)
+		if status != tt.status || body != tt.body {
+			t.Errorf("GET %s: expected %d %q, got %d %q", tt.path, tt.status, tt.body, status, body)
+		}
+	}
+
+	// Once all runs were served the last one repeats
+	if _, body := getBody(t, replayer, WithSequence(context.Background(), "feed"), server.URL+"/page1"); body != "/page1 3" {
+		t.Errorf("Expected the last run to repeat, got %q", body)
+	}
+	if requests.Load() != 4 {
+		t.Errorf("Expected replay not to reach the network, got %d requests", requests.Load())
+	}
+}
+
+func TestRecord_WithoutValidators(t *testing.T) {
+	var conditional atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" {
+			conditional.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	client := &http.Client{}
+	Record(client, dir, log.New(io.Discard, "", 0))
+	for range 2 {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		req.Header.Set("If-None-Match", `"v1"`)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("GET failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if conditional.Load() != 0 {
+		t.Errorf("Expected recorded requests to be sent without validators, got %d conditional requests", conditional.Load())
+	}
+	recordings, _ := LoadRecordings(dir)
+	for _, recording := range recordings {
+		if recording.Status != http.StatusOK || recording.Body == "" {
+			t.Errorf("Expected every recording to have a body, got status %d", recording.Status)
+		}
+	}
+}
+
+func TestReplayTransport_SkipsNotModified(t *testing.T) {
+	url := "https://itunes.example/feed"
+	transport := &replayTransport{
+		runs:   make(map[string][]replayRun),
+		next:   make(map[string]int),
+		live:   make(map[string]*liveRun),
+		logger: log.New(io.Discard, "", 0),
+	}
+	transport.add([]Recording{
+		{URL: url, Status: http.StatusOK, Body: "first", Sequence: "feed", Run: "1"},
+		{URL: url, Status: http.StatusNotModified, Sequence: "feed", Run: "1"},
+	})
+	ctx := WithSequence(context.Background(), "feed")
+
+	for i := range 2 {
+		req := httptest.NewRequest(http.MethodGet, url, nil).WithContext(ctx)
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("RoundTrip failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK || string(body) != "first" {
+			t.Errorf("Request %d: expected the recording with a body, got status %d body %q", i+1, resp.StatusCode, body)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, url, nil).WithContext(ctx)
+	req.Header.Set("If-None-Match", `"v1"`)
+	resp, _ := transport.RoundTrip(req)
+	if resp.StatusCode != http.StatusNotModified {
+		t.Errorf("Expected the recorded 304 for a conditional request, got %d", resp.StatusCode)
+	}
+}
+
+func TestLoadRecordings_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0644)
+
+	if _, err := LoadRecordings(dir); err == nil {
+		t.Error("Expected error for an invalid recording, got nil")
+	}
+
+	client := &http.Client{}
+	if err := Replay(client, dir, log.New(io.Discard, "", 0)); err == nil {
+		t.Error("Expected Replay to report the invalid recording, got nil")
+	}
+	if client.Transport == nil {
+		t.Error("Expected the client to replay without recordings")
+	}
+}
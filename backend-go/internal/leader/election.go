@@ -0,0 +1,318 @@
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultLeaseDuration is how long a lease stays valid without being renewed
+const DefaultLeaseDuration = 15 * time.Second
+
+// DefaultRenewInterval is how often the leader renews its lease and followers
+// check whether it expired
+const DefaultRenewInterval = 5 * time.Second
+
+// Lease is the content of the lock file: which instance leads and until when
+type Lease struct {
+	Holder     string    `json:"holder"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	RenewedAt  time.Time `json:"renewed_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the lease is no longer valid at now, so another
+// instance may take over
+func (l Lease) Expired(now time.Time) bool {
+	return !now.Before(l.ExpiresAt)
+}
+
+// ReadLease returns the lease in the lock file at path without competing
+// for it. A missing file is a free lease.
+func ReadLease(path string) (Lease, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Lease{}, nil
+	}
+	if err != nil {
+		return Lease{}, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	defer file.Close()
+
+	// The leader rewrites the lease in place, wait until it is done
+	if err := lockFile(file); err != nil {
+		return Lease{}, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	defer unlockFile(file)
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return Lease{}, fmt.Errorf("failed to read lock file: %w", err)
+	}
+
+	var lease Lease
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &lease); err != nil {
+			return Lease{}, fmt.Errorf("failed to decode lock file: %w", err)
+		}
+	}
+	return lease, nil
+}
+
+// Candidate is the part of an instance that only runs while it leads
+type Candidate interface {
+	// StartLeading is called when the instance became the leader
+	StartLeading()
+	// StopLeading is called when the instance lost its lease or shuts down.
+	// ctx is done when the lease expires, after which another instance may
+	// lead, so work still running then has to be cancelled.
+	StopLeading(ctx context.Context)
+	// Following is called on every lease check while another instance leads
+	Following(leader Lease)
+}
+
+// Elector elects a single leader among the instances sharing a lock file.
+// The file holds a lease that the leader renews; changes to it are guarded
+// by an advisory lock. When the leader stops renewing, another instance
+// takes over once the lease expired, i.e. at most lease duration + renew
+// interval after the last renewal.
+//
+// Lease expiry is compared across instances, so their clocks must agree.
+type Elector struct {
+	path          string
+	id            string
+	leaseDuration time.Duration
+	logger        *log.Logger
+
+	mu        sync.RWMutex
+	leading   bool
+	expiresAt time.Time // Expiry of our lease while leading
+	leader    Lease     // Lease last seen in the lock file
+}
+
+// NewElector competes for the lease in the lock file at path as the
+// instance id, which must be unique among the instances
+func NewElector(path, id string, leaseDuration time.Duration, logger *log.Logger) *Elector {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &Elector{
+		path:          path,
+		id:            id,
+		leaseDuration: leaseDuration,
+		logger:        logger,
+	}
+}
+
+// DefaultID identifies this instance by host name and process ID
+func DefaultID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// ID returns the ID this instance competes for the lease with
+func (e *Elector) ID() string {
+	return e.id
+}
+
+// IsLeader reports whether this instance currently holds the lease
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leading
+}
+
+// Leader returns the lease last seen in the lock file
+func (e *Elector) Leader() Lease {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+// Run competes for the lease right away and then every renewInterval until
+// stop is closed, telling candidate when leadership changes. On stop the
+// lease is released so another instance can take over without waiting for
+// it to expire.
+func (e *Elector) Run(renewInterval time.Duration, candidate Candidate, stop <-chan struct{}) {
+	ticker := time.NewTicker(renewInterval)
+	defer ticker.Stop()
+
+	for {
+		e.check(renewInterval, candidate)
+
+		select {
+		case <-stop:
+			e.resign(candidate)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check acquires or renews the lease and tells candidate what changed
+func (e *Elector) check(renewInterval time.Duration, candidate Candidate) {
+	now := time.Now()
+	lease, err := e.acquire(now)
+	if err != nil {
+		e.logger.Printf("Error checking leader lease: %v", err)
+
+		// Keep leading while the lease lasts, but step down before the next
+		// check would come too late to stop us ahead of a new leader
+		e.mu.RLock()
+		expiring := e.leading && !now.Add(renewInterval).Before(e.expiresAt)
+		e.mu.RUnlock()
+		if expiring {
+			e.logger.Println("Leader lease could not be renewed, stepping down")
+			e.stepDown(candidate)
+		}
+		return
+	}
+
+	e.mu.Lock()
+	e.leader = lease
+	wasLeading := e.leading
+	if lease.Holder == e.id {
+		e.leading = true
+		e.expiresAt = lease.ExpiresAt
+	}
+	e.mu.Unlock()
+
+	if lease.Holder == e.id {
+		if !wasLeading {
+			e.logger.Printf("Elected leader as %s", e.id)
+			candidate.StartLeading()
+		}
+		return
+	}
+
+	if wasLeading {
+		e.logger.Printf("Lost leadership to %s", lease.Holder)
+		e.stepDown(candidate)
+	}
+	candidate.Following(lease)
+}
+
+// stepDown stops leading, giving candidate until our lease expires
+func (e *Elector) stepDown(candidate Candidate) {
+	e.mu.Lock()
+	e.leading = false
+	expiresAt := e.expiresAt
+	e.mu.Unlock()
+
+	ctx, cancel := context.WithDeadline(context.Background(), expiresAt)
+	defer cancel()
+	candidate.StopLeading(ctx)
+}
+
+// resign stops leading and releases the lease, if we hold it
+func (e *Elector) resign(candidate Candidate) {
+	if !e.IsLeader() {
+		return
+	}
+	e.stepDown(candidate)
+
+	if err := e.release(time.Now()); err != nil {
+		e.logger.Printf("Error releasing leader lease: %v", err)
+		return
+	}
+	e.logger.Println("Released leader lease")
+}
+
+// acquire takes the lease if it is free or expired and renews it if we hold
+// it. It returns the lease in the lock file afterwards.
+func (e *Elector) acquire(now time.Time) (Lease, error) {
+	return e.update(func(current Lease) (Lease, bool) {
+		expired := current.Expired(now)
+		if current.Holder != e.id && !expired {
+			return current, false // Another instance leads
+		}
+
+		acquiredAt := current.AcquiredAt
+		if current.Holder != e.id || expired {
+			acquiredAt = now
+		}
+		return Lease{
+			Holder:     e.id,
+			AcquiredAt: acquiredAt,
+			RenewedAt:  now,
+			ExpiresAt:  now.Add(e.leaseDuration),
+		}, true
+	})
+}
+
+// release lets our lease expire right away
+func (e *Elector) release(now time.Time) error {
+	_, err := e.update(func(current Lease) (Lease, bool) {
+		if current.Holder != e.id {
+			return current, false // Already taken over
+		}
+		current.ExpiresAt = now
+		return current, true
+	})
+	return err
+}
+
+// update changes the lease in the lock file while holding the advisory lock.
+// change returns the new lease and whether it has to be written.
+func (e *Elector) update(change func(current Lease) (Lease, bool)) (Lease, error) {
+	if err := os.MkdirAll(filepath.Dir(e.path), 0755); err != nil {
+		return Lease{}, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// The lease is rewritten in place: a temp file + rename would replace the
+	// file other instances hold the lock on
+	file, err := os.OpenFile(e.path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return Lease{}, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	defer file.Close()
+
+	if err := lockFile(file); err != nil {
+		return Lease{}, fmt.Errorf("failed to lock %s: %w", e.path, err)
+	}
+	defer unlockFile(file)
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return Lease{}, fmt.Errorf("failed to read lock file: %w", err)
+	}
+
+	var current Lease
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &current); err != nil {
+			// Treated as free, the next leader overwrites it
+			e.logger.Printf("Warning: ignoring unreadable leader lease: %v", err)
+			current = Lease{}
+		}
+	}
+
+	next, write := change(current)
+	if !write {
+		return current, nil
+	}
+
+	data, err = json.MarshalIndent(next, "", "  ")
+	if err != nil {
+		return Lease{}, fmt.Errorf("failed to marshal lease: %w", err)
+	}
+	if err := file.Truncate(0); err != nil {
+		return Lease{}, fmt.Errorf("failed to write lock file: %w", err)
+	}
+	if _, err := file.WriteAt(data, 0); err != nil {
+		return Lease{}, fmt.Errorf("failed to write lock file: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return Lease{}, fmt.Errorf("failed to sync lock file: %w", err)
+	}
+	return next, nil
+}
@@ -0,0 +1,252 @@
+package leader
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeCandidate records the leadership changes it is told about
+type fakeCandidate struct {
+	mu        sync.Mutex
+	leading   bool
+	started   int
+	stopped   int
+	followed  string
+	stopCtx   context.Context
+	elected   chan struct{}
+	resigned  chan struct{}
+	following chan struct{}
+}
+
+func newFakeCandidate() *fakeCandidate {
+	return &fakeCandidate{
+		elected:   make(chan struct{}, 10),
+		resigned:  make(chan struct{}, 10),
+		following: make(chan struct{}, 100),
+	}
+}
+
+func (c *fakeCandidate) StartLeading() {
+	c.mu.Lock()
+	c.leading = true
+	c.started++
+	c.mu.Unlock()
+	c.elected <- struct{}{}
+}
+
+func (c *fakeCandidate) StopLeading(ctx context.Context) {
+	c.mu.Lock()
+	c.leading = false
+	c.stopped++
+	c.stopCtx = ctx
+	c.mu.Unlock()
+	c.resigned <- struct{}{}
+}
+
+func (c *fakeCandidate) Following(leader Lease) {
+	c.mu.Lock()
+	c.followed = leader.Holder
+	c.mu.Unlock()
+	select {
+	case c.following <- struct{}{}:
+	default:
+	}
+}
+
+func wait(t *testing.T, ch <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-ch:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Timed out waiting for %s", what)
+	}
+}
+
+func newTestElector(path, id string, leaseDuration time.Duration) *Elector {
+	return NewElector(path, id, leaseDuration, log.New(io.Discard, "", 0))
+}
+
+func TestElector_SingleLeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lock")
+	a := newTestElector(path, "a", time.Minute)
+	b := newTestElector(path, "b", time.Minute)
+	candidateA := newFakeCandidate()
+	candidateB := newFakeCandidate()
+
+	a.check(time.Second, candidateA)
+	b.check(time.Second, candidateB)
+	a.check(time.Second, candidateA) // Renewal
+
+	if !a.IsLeader() || b.IsLeader() {
+		t.Fatalf("Expected a to lead and b to follow, got a=%v b=%v", a.IsLeader(), b.IsLeader())
+	}
+	if candidateA.started != 1 || candidateB.started != 0 {
+		t.Errorf("Expected only a to start leading once, got a=%d b=%d", candidateA.started, candidateB.started)
+	}
+	if candidateB.followed != "a" || b.Leader().Holder != "a" {
+		t.Errorf("Expected b to follow a, got %q", candidateB.followed)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read lock file: %v", err)
+	}
+	var lease Lease
+	if err := json.Unmarshal(data, &lease); err != nil {
+		t.Fatalf("Invalid lease in lock file: %v", err)
+	}
+	if lease.Holder != "a" || !lease.RenewedAt.After(lease.AcquiredAt) || !lease.ExpiresAt.After(time.Now()) {
+		t.Errorf("Unexpected lease %+v", lease)
+	}
+}
+
+func TestElector_TakeoverAfterLeaderDies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lock")
+	leaseDuration := 100 * time.Millisecond
+	renewInterval := 20 * time.Millisecond
+
+	// a leads once and then stops renewing, as if the process died
+	dead := newTestElector(path, "a", leaseDuration)
+	dead.check(renewInterval, newFakeCandidate())
+	diedAt := time.Now()
+
+	follower := newTestElector(path, "b", leaseDuration)
+	candidate := newFakeCandidate()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		follower.Run(renewInterval, candidate, stop)
+		close(done)
+	}()
+
+	wait(t, candidate.following, "b to follow a")
+	wait(t, candidate.elected, "b to take over")
+	if took := time.Since(diedAt); took > leaseDuration+renewInterval+100*time.Millisecond {
+		t.Errorf("Expected takeover within lease duration + renew interval, took %v", took)
+	}
+
+	close(stop)
+	<-done
+	if candidate.stopped != 1 {
+		t.Errorf("Expected b to stop leading on stop, got %d", candidate.stopped)
+	}
+}
+
+func TestElector_ReleasesLeaseOnStop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lock")
+	a := newTestElector(path, "a", time.Minute)
+	candidate := newFakeCandidate()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		a.Run(time.Hour, candidate, stop)
+		close(done)
+	}()
+	wait(t, candidate.elected, "a to be elected")
+
+	close(stop)
+	<-done
+	if a.IsLeader() {
+		t.Error("Expected a to stop leading")
+	}
+	if deadline, ok := candidate.stopCtx.Deadline(); !ok || !deadline.After(time.Now()) {
+		t.Errorf("Expected StopLeading to get until the lease expires, got deadline %v", deadline)
+	}
+
+	// The lease was released, so b does not have to wait a minute
+	b := newTestElector(path, "b", time.Minute)
+	b.check(time.Hour, newFakeCandidate())
+	if !b.IsLeader() {
+		t.Error("Expected b to take over the released lease right away")
+	}
+}
+
+func TestElector_StepsDownWhenLeaseTaken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lock")
+	a := newTestElector(path, "a", time.Minute)
+	candidate := newFakeCandidate()
+	a.check(time.Second, candidate)
+	if !a.IsLeader() {
+		t.Fatal("Expected a to lead")
+	}
+
+	// Another instance took over, e.g. after a stalled a let its lease expire
+	now := time.Now()
+	data, _ := json.Marshal(Lease{Holder: "b", AcquiredAt: now, RenewedAt: now, ExpiresAt: now.Add(time.Minute)})
+	os.WriteFile(path, data, 0644)
+
+	a.check(time.Second, candidate)
+	if a.IsLeader() || candidate.leading {
+		t.Error("Expected a to step down")
+	}
+	if candidate.followed != "b" {
+		t.Errorf("Expected a to follow b, got %q", candidate.followed)
+	}
+}
+
+func TestElector_StepsDownWhenLeaseCannotBeRenewed(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "leader.lock")
+	a := newTestElector(path, "a", 50*time.Millisecond)
+	candidate := newFakeCandidate()
+	a.check(10*time.Millisecond, candidate)
+	if !a.IsLeader() {
+		t.Fatal("Expected a to lead")
+	}
+
+	// Make the lock file unreadable, e.g. like a shared volume going away
+	os.Remove(path)
+	os.Mkdir(path, 0755)
+
+	a.check(10*time.Millisecond, candidate)
+	if !a.IsLeader() {
+		t.Error("Expected a to keep leading while its lease lasts")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	a.check(10*time.Millisecond, candidate)
+	if a.IsLeader() || candidate.stopped != 1 {
+		t.Errorf("Expected a to step down before its lease expires, leading=%v stopped=%d", a.IsLeader(), candidate.stopped)
+	}
+}
+
+func TestElector_UnreadableLeaseIsFree(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lock")
+	os.WriteFile(path, []byte("not a lease"), 0644)
+
+	a := newTestElector(path, "a", time.Minute)
+	a.check(time.Second, newFakeCandidate())
+	if !a.IsLeader() {
+		t.Error("Expected a to take over a lock file without a valid lease")
+	}
+}
+
+func TestReadLease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leader.lock")
+
+	lease, err := ReadLease(path)
+	if err != nil || !lease.Expired(time.Now()) {
+		t.Fatalf("Expected a missing lock file to be a free lease, got %+v, %v", lease, err)
+	}
+
+	a := newTestElector(path, "a", time.Minute)
+	a.check(time.Hour, newFakeCandidate())
+
+	lease, err = ReadLease(path)
+	if err != nil {
+		t.Fatalf("ReadLease failed: %v", err)
+	}
+	if lease.Holder != "a" || lease.Expired(time.Now()) {
+		t.Errorf("Expected the lease held by a, got %+v", lease)
+	}
+	if !lease.Expired(time.Now().Add(2 * time.Minute)) {
+		t.Error("Expected the lease to expire after its duration")
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package leader
+
+import (
+	"errors"
+	"os"
+)
+
+// lockFile is not implemented outside of unix, so an elector never leads there
+func lockFile(file *os.File) error {
+	return errors.ErrUnsupported
+}
+
+func unlockFile(file *os.File) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,380 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/httpclient"
+	"backend/internal/models"
+	"backend/internal/storage"
+)
+
+// DefaultBaseURL is the iTunes host serving the Lookup API
+const DefaultBaseURL = "https://itunes.apple.com"
+
+// DefaultRefreshInterval is how often the metadata of all apps is fetched again
+const DefaultRefreshInterval = 24 * time.Hour
+
+// lookupBatchSize is the number of app IDs requested in a single lookup
+const lookupBatchSize = 100
+
+// newAppCheckInterval is how often the registry looks for apps it has not
+// fetched yet, so apps added at runtime do not wait for the next refresh
+var newAppCheckInterval = time.Minute
+
+// AppLister provides the apps whose metadata is kept
+type AppLister interface {
+	Apps() []models.App
+}
+
+// Registry keeps the store metadata (name, developer, icon, ...) of the
+// tracked iOS apps, fetched from the iTunes Lookup API. With a path set it is
+// persisted to disk so the metadata is available right after a restart.
+type Registry struct {
+	mu        sync.RWMutex
+	path      string
+	baseURL   string
+	client    *http.Client
+	userAgent string
+	apps      AppLister
+	logger    *log.Logger
+	entries   map[string]models.AppMetadata
+	attempted map[string]bool // Apps the lookup answered for since the start, found or not
+}
+
+// Option customizes a Registry created by NewRegistry
+type Option func(*Registry)
+
+// WithBaseURL overrides the Lookup API host (e.g. to test against a local fake)
+func WithBaseURL(baseURL string) Option {
+	return func(r *Registry) {
+		r.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithHTTPClient sends the lookups through client, e.g. one built by
+// httpclient.New with a proxy or custom CA
+func WithHTTPClient(client *http.Client) Option {
+	return func(r *Registry) {
+		r.client = client
+	}
+}
+
+// WithUserAgent sets the User-Agent header of the lookups
+func WithUserAgent(userAgent string) Option {
+	return func(r *Registry) {
+		r.userAgent = userAgent
+	}
+}
+
+func NewRegistry(path string, apps AppLister, logger *log.Logger, opts ...Option) *Registry {
+	if logger == nil {
+		logger = log.Default()
+	}
+	r := &Registry{
+		path:    path,
+		baseURL: DefaultBaseURL,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		userAgent: httpclient.DefaultUserAgent,
+		apps:      apps,
+		logger:    logger,
+		entries:   make(map[string]models.AppMetadata),
+		attempted: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Load reads the metadata saved by a previous run, replacing the metadata in
+// memory. A missing file is not an error.
+func (r *Registry) Load() error {
+	if r.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(r.path)
+	if os.IsNotExist(err) {
+		return nil // First run
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read app metadata: %w", err)
+	}
+
+	var entries map[string]models.AppMetadata
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to unmarshal app metadata: %w", err)
+	}
+
+	if entries == nil {
+		entries = make(map[string]models.AppMetadata)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = entries
+	return nil
+}
+
+// Get returns the metadata of an app, false when it has not been fetched
+func (r *Registry) Get(appID string) (models.AppMetadata, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, ok := r.entries[appID]
+	return entry, ok
+}
+
+// Run refreshes the metadata of all apps right away and then every interval,
+// looking up newly added apps in between, until stop is closed. Closing stop
+// aborts a running lookup, whose results are then not persisted.
+func (r *Registry) Run(interval time.Duration, stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	r.refreshLogged(ctx, true)
+	lastRefresh := time.Now()
+
+	ticker := time.NewTicker(min(interval, newAppCheckInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			if now.Sub(lastRefresh) >= interval {
+				r.refreshLogged(ctx, true)
+				lastRefresh = now
+			} else {
+				r.refreshLogged(ctx, false)
+			}
+		}
+	}
+}
+
+func (r *Registry) refreshLogged(ctx context.Context, all bool) {
+	var err error
+	if all {
+		err = r.Refresh(ctx)
+	} else {
+		err = r.refreshNew(ctx)
+	}
+	if err != nil && ctx.Err() == nil {
+		r.logger.Printf("Error refreshing app metadata: %v", err)
+	}
+}
+
+// Refresh fetches the metadata of all tracked iOS apps and drops the metadata
+// of apps that are no longer tracked. Apps whose lookup fails keep their
+// previous metadata.
+func (r *Registry) Refresh(ctx context.Context) error {
+	apps := iosApps(r.apps.Apps())
+
+	tracked := make(map[string]bool, len(apps))
+	for _, app := range apps {
+		tracked[app.ID] = true
+	}
+	r.mu.Lock()
+	for id := range r.entries {
+		if !tracked[id] {
+			delete(r.entries, id)
+		}
+	}
+	r.mu.Unlock()
+
+	return r.refresh(ctx, apps)
+}
+
+// refreshNew fetches the metadata of tracked apps not looked up yet, retrying
+// those whose lookup failed
+func (r *Registry) refreshNew(ctx context.Context) error {
+	tracked := iosApps(r.apps.Apps())
+
+	var apps []models.App
+	r.mu.RLock()
+	for _, app := range tracked {
+		if !r.attempted[app.ID] {
+			apps = append(apps, app)
+		}
+	}
+	r.mu.RUnlock()
+
+	if len(apps) == 0 {
+		return nil
+	}
+	return r.refresh(ctx, apps)
+}
+
+// refresh looks up the apps in batches per storefront and persists the result,
+// unless ctx was cancelled meanwhile. The metadata is taken from the first
+// storefront of each app.
+func (r *Registry) refresh(ctx context.Context, apps []models.App) error {
+	byCountry := make(map[string][]string)
+	var countries []string
+	for _, app := range apps {
+		country := app.StoreCountries()[0]
+		if _, ok := byCountry[country]; !ok {
+			countries = append(countries, country)
+		}
+		byCountry[country] = append(byCountry[country], app.ID)
+	}
+
+	var errs []error
+	for _, country := range countries {
+		ids := byCountry[country]
+		for start := 0; start < len(ids); start += lookupBatchSize {
+			batch := ids[start:min(start+lookupBatchSize, len(ids))]
+			if err := r.refreshBatch(ctx, country, batch); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	// Whoever stopped us may no longer be allowed to write the file
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := r.persist(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Registry) refreshBatch(ctx context.Context, country string, ids []string) error {
+	entries, err := r.lookup(ctx, country, ids)
+	if err != nil {
+		return fmt.Errorf("lookup in %s failed: %w", country, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range ids {
+		r.attempted[id] = true
+		entry, ok := entries[id]
+		if !ok {
+			r.logger.Printf("Warning: app %s not found in the %s store", id, country)
+			continue
+		}
+		r.entries[id] = entry
+	}
+	return nil
+}
+
+// lookupResponse is the part of the iTunes Lookup API response we use
+type lookupResponse struct {
+	Results []struct {
+		TrackID          int64  `json:"trackId"`
+		TrackName        string `json:"trackName"`
+		ArtistName       string `json:"artistName"`
+		ArtworkURL512    string `json:"artworkUrl512"`
+		ArtworkURL100    string `json:"artworkUrl100"`
+		ArtworkURL60     string `json:"artworkUrl60"`
+		Version          string `json:"version"`
+		PrimaryGenreName string `json:"primaryGenreName"`
+		TrackViewURL     string `json:"trackViewUrl"`
+	} `json:"results"`
+}
+
+// lookup fetches the metadata of the apps from one storefront, keyed by app ID
+func (r *Registry) lookup(ctx context.Context, country string, ids []string) (map[string]models.AppMetadata, error) {
+	query := neturl.Values{}
+	query.Set("id", strings.Join(ids, ","))
+	query.Set("country", country)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.baseURL+"/lookup?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", r.userAgent)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var decoded lookupResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode lookup response: %w", err)
+	}
+
+	now := time.Now()
+	entries := make(map[string]models.AppMetadata, len(decoded.Results))
+	for _, result := range decoded.Results {
+		id := strconv.FormatInt(result.TrackID, 10)
+		entries[id] = models.AppMetadata{
+			AppID:     id,
+			Name:      result.TrackName,
+			Developer: result.ArtistName,
+			IconURL:   firstNonEmpty(result.ArtworkURL512, result.ArtworkURL100, result.ArtworkURL60),
+			Version:   result.Version,
+			Genre:     result.PrimaryGenreName,
+			StoreURL:  result.TrackViewURL,
+			UpdatedAt: now,
+		}
+	}
+	return entries, nil
+}
+
+// persist writes the metadata to disk
+func (r *Registry) persist() error {
+	if r.path == "" {
+		return nil
+	}
+
+	r.mu.RLock()
+	data, err := json.MarshalIndent(r.entries, "", "  ")
+	r.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal app metadata: %w", err)
+	}
+
+	return storage.WriteFileAtomic(r.path, data)
+}
+
+// iosApps returns the apps listed in the App Store
+func iosApps(apps []models.App) []models.App {
+	var ios []models.App
+	for _, app := range apps {
+		if app.StorePlatform() == models.PlatformIOS {
+			ios = append(ios, app)
+		}
+	}
+	return ios
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,256 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+)
+
+// fakeApps is a fixed AppLister
+type fakeApps struct {
+	mu   sync.Mutex
+	apps []models.App
+}
+
+func (f *fakeApps) Apps() []models.App {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]models.App(nil), f.apps...)
+}
+
+func (f *fakeApps) set(apps ...models.App) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.apps = apps
+}
+
+// newLookupServer serves lookup results for known app IDs, with names
+// suffixed by the requested storefront
+func newLookupServer(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests != nil {
+			requests.Add(1)
+		}
+		if r.URL.Path != "/lookup" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		country := r.URL.Query().Get("country")
+		if country == "xx" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		var results []string
+		for _, id := range strings.Split(r.URL.Query().Get("id"), ",") {
+			if id == "999" {
+				continue // Not in the store
+			}
+			results = append(results, fmt.Sprintf(`{
+				"trackId": %s, "trackName": "App %s %s", "artistName": "Developer %s",
+				"artworkUrl100": "https://example.com/%s/100.png", "artworkUrl512": "https://example.com/%s/512.png",
+				"version": "1.2.3", "primaryGenreName": "Productivity",
+				"trackViewUrl": "https://apps.apple.com/%s/app/id%s"
+			}`, id, id, country, id, id, id, country, id))
+		}
+		fmt.Fprintf(w, `{"resultCount": %d, "results": [%s]}`, len(results), strings.Join(results, ","))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRegistry_Refresh(t *testing.T) {
+	server := newLookupServer(t, nil)
+	apps := &fakeApps{apps: []models.App{
+		{ID: "123", Countries: []string{"de", "us"}},
+		{ID: "456"},
+		{ID: "com.example.app", Platform: models.PlatformAndroid},
+	}}
+	registry := NewRegistry("", apps, log.New(io.Discard, "", 0), WithBaseURL(server.URL))
+
+	if err := registry.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	entry, ok := registry.Get("123")
+	if !ok {
+		t.Fatal("Expected metadata for app 123")
+	}
+	expected := models.AppMetadata{
+		AppID:     "123",
+		Name:      "App 123 de",
+		Developer: "Developer 123",
+		IconURL:   "https://example.com/123/512.png",
+		Version:   "1.2.3",
+		Genre:     "Productivity",
+		StoreURL:  "https://apps.apple.com/de/app/id123",
+		UpdatedAt: entry.UpdatedAt,
+	}
+	if entry != expected || entry.UpdatedAt.IsZero() {
+		t.Errorf("Expected %+v, got %+v", expected, entry)
+	}
+	if entry, ok := registry.Get("456"); !ok || entry.Name != "App 456 us" {
+		t.Errorf("Expected app 456 from the default storefront, got %+v", entry)
+	}
+	if _, ok := registry.Get("com.example.app"); ok {
+		t.Error("Expected no metadata for the Android app")
+	}
+}
+
+func TestRegistry_RefreshKeepsMetadataOnFailure(t *testing.T) {
+	server := newLookupServer(t, nil)
+	apps := &fakeApps{apps: []models.App{{ID: "123"}, {ID: "999"}}}
+	registry := NewRegistry("", apps, log.New(io.Discard, "", 0), WithBaseURL(server.URL))
+	registry.entries["999"] = models.AppMetadata{AppID: "999", Name: "Delisted"}
+	registry.entries["456"] = models.AppMetadata{AppID: "456", Name: "Untracked"}
+
+	if err := registry.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if entry, _ := registry.Get("999"); entry.Name != "Delisted" {
+		t.Errorf("Expected metadata of an app missing from the store to be kept, got %+v", entry)
+	}
+	if _, ok := registry.Get("456"); ok {
+		t.Error("Expected metadata of an untracked app to be dropped")
+	}
+
+	apps.set(models.App{ID: "123", Countries: []string{"xx"}})
+	if err := registry.Refresh(context.Background()); err == nil {
+		t.Fatal("Expected error for a failed lookup, got nil")
+	}
+	if entry, _ := registry.Get("123"); entry.Name != "App 123 us" {
+		t.Errorf("Expected metadata to be kept after a failed lookup, got %+v", entry)
+	}
+}
+
+func TestRegistry_PersistsMetadata(t *testing.T) {
+	server := newLookupServer(t, nil)
+	path := filepath.Join(t.TempDir(), "app_metadata.json")
+	apps := &fakeApps{apps: []models.App{{ID: "123"}}}
+
+	registry := NewRegistry(path, apps, log.New(io.Discard, "", 0), WithBaseURL(server.URL))
+	if err := registry.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	// A new registry serves the saved metadata before its first refresh
+	loaded := NewRegistry(path, apps, log.New(io.Discard, "", 0), WithBaseURL(server.URL))
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if entry, ok := loaded.Get("123"); !ok || entry.Name != "App 123 us" {
+		t.Errorf("Expected the saved metadata of app 123, got %+v", entry)
+	}
+
+	// Loading again replaces metadata of apps the file no longer has
+	loaded.entries["456"] = models.AppMetadata{AppID: "456", Name: "Dropped"}
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, ok := loaded.Get("456"); ok {
+		t.Error("Expected Load to drop metadata missing from the file")
+	}
+
+	missing := NewRegistry(filepath.Join(t.TempDir(), "missing.json"), apps, nil)
+	if err := missing.Load(); err != nil {
+		t.Errorf("Expected no error for a missing file, got %v", err)
+	}
+}
+
+func TestRegistry_RetriesFailedLookupsOfNewApps(t *testing.T) {
+	server := newLookupServer(t, nil)
+	apps := &fakeApps{apps: []models.App{{ID: "123", Countries: []string{"xx"}}}}
+	registry := NewRegistry("", apps, log.New(io.Discard, "", 0), WithBaseURL(server.URL))
+
+	if err := registry.refreshNew(context.Background()); err == nil {
+		t.Fatal("Expected error for a failed lookup, got nil")
+	}
+
+	// The next check looks the app up again
+	apps.set(models.App{ID: "123"})
+	if err := registry.refreshNew(context.Background()); err != nil {
+		t.Fatalf("refreshNew failed: %v", err)
+	}
+	if entry, ok := registry.Get("123"); !ok || entry.Name != "App 123 us" {
+		t.Errorf("Expected the app to be looked up after the failure, got %+v", entry)
+	}
+}
+
+func TestRegistry_CancelledRefreshIsNotPersisted(t *testing.T) {
+	server := newLookupServer(t, nil)
+	path := filepath.Join(t.TempDir(), "app_metadata.json")
+	apps := &fakeApps{apps: []models.App{{ID: "123"}}}
+	registry := NewRegistry(path, apps, log.New(io.Discard, "", 0), WithBaseURL(server.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := registry.Refresh(ctx); err == nil {
+		t.Fatal("Expected error for a cancelled refresh, got nil")
+	}
+	if _, ok := registry.Get("123"); ok {
+		t.Error("Expected no metadata from a cancelled refresh")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected a cancelled refresh not to write the file, got %v", err)
+	}
+}
+
+func TestRegistry_RunLooksUpNewApps(t *testing.T) {
+	original := newAppCheckInterval
+	newAppCheckInterval = 10 * time.Millisecond
+	defer func() { newAppCheckInterval = original }()
+
+	var requests atomic.Int32
+	server := newLookupServer(t, &requests)
+	apps := &fakeApps{apps: []models.App{{ID: "123"}}}
+	registry := NewRegistry("", apps, log.New(io.Discard, "", 0), WithBaseURL(server.URL))
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		registry.Run(time.Hour, stop)
+		close(done)
+	}()
+	defer func() {
+		close(stop)
+		<-done
+	}()
+
+	waitFor := func(id string) bool {
+		deadline := time.Now().Add(time.Second)
+		for time.Now().Before(deadline) {
+			if _, ok := registry.Get(id); ok {
+				return true
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		return false
+	}
+
+	if !waitFor("123") {
+		t.Fatal("Expected the initial refresh to fetch app 123")
+	}
+	apps.set(models.App{ID: "123"}, models.App{ID: "456"})
+	if !waitFor("456") {
+		t.Fatal("Expected the added app 456 to be looked up")
+	}
+
+	// Known apps are not looked up again until the next refresh
+	time.Sleep(50 * time.Millisecond)
+	if requests.Load() != 2 {
+		t.Errorf("Expected 2 lookups, got %d", requests.Load())
+	}
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// DefaultCountry is the storefront polled when an app does not list any countries
+const DefaultCountry = "us"
+
+// Platforms whose stores reviews are fetched from
+const (
+	PlatformIOS     = "ios"     // App Store, app IDs are numeric iTunes IDs
+	PlatformAndroid = "android" // Google Play, app IDs are package names
+)
+
+// App describes a tracked app and the storefronts its reviews are fetched from
+type App struct {
+	ID        string   `json:"id"`                  // iTunes app ID or Android package name
+	Platform  string   `json:"platform,omitempty"`  // PlatformIOS (default) or PlatformAndroid
+	Countries []string `json:"countries,omitempty"` // Storefront country codes (e.g. "us", "de"), iOS only
+	Interval  Duration `json:"interval,omitempty"`  // Poll interval, the poller default when zero
+}
+
+// AppMetadata describes an app as listed in its store
+type AppMetadata struct {
+	AppID     string    `json:"app_id"`
+	Name      string    `json:"name"`
+	Developer string    `json:"developer"`
+	IconURL   string    `json:"icon_url"`
+	Version   string    `json:"version"`    // Current version in the store
+	Genre     string    `json:"genre"`      // Primary store category
+	StoreURL  string    `json:"store_url"`  // App page in the store
+	UpdatedAt time.Time `json:"updated_at"` // When the metadata was last fetched
+}
+
+// StorePlatform returns the normalized platform of the app, defaulting to PlatformIOS
+func (a App) StorePlatform() string {
+	platform := strings.ToLower(strings.TrimSpace(a.Platform))
+	if platform == "" {
+		return PlatformIOS
+	}
+	return platform
+}
+
+// StoreCountries returns the normalized storefront codes to poll for the app,
+// falling back to DefaultCountry when none are configured
+func (a App) StoreCountries() []string {
+	countries := make([]string, 0, len(a.Countries))
+	seen := make(map[string]bool, len(a.Countries))
+
+	for _, country := range a.Countries {
+		country = strings.ToLower(strings.TrimSpace(country))
+		if country == "" || seen[country] {
+			continue
+		}
+		seen[country] = true
+		countries = append(countries, country)
+	}
+
+	if len(countries) == 0 {
+		return []string{DefaultCountry}
+	}
+	return countries
+}
+
+// UnmarshalJSON accepts both the object form and a bare app ID string,
+// so configs written before countries were supported keep loading
+func (a *App) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil {
+		*a = App{ID: id}
+		return nil
+	}
+
+	// Use an alias type to avoid recursing into this method
+	type app App
+	var decoded app
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*a = App(decoded)
+	return nil
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestApp_StoreCountries(t *testing.T) {
+	app := App{ID: "123", Countries: []string{"us", " DE ", "fr", "US", ""}}
+
+	countries := app.StoreCountries()
+
+	expected := []string{"us", "de", "fr"}
+	if !reflect.DeepEqual(countries, expected) {
+		t.Errorf("Expected countries %v, got %v", expected, countries)
+	}
+}
+
+func TestApp_StoreCountriesDefault(t *testing.T) {
+	app := App{ID: "123"}
+
+	countries := app.StoreCountries()
+
+	if !reflect.DeepEqual(countries, []string{DefaultCountry}) {
+		t.Errorf("Expected default country %q, got %v", DefaultCountry, countries)
+	}
+}
+
+func TestApp_StorePlatform(t *testing.T) {
+	tests := map[string]string{
+		"":          PlatformIOS,
+		"ios":       PlatformIOS,
+		" Android ": PlatformAndroid,
+	}
+
+	for platform, expected := range tests {
+		app := App{ID: "123", Platform: platform}
+		if got := app.StorePlatform(); got != expected {
+			t.Errorf("Platform %q: expected %q, got %q", platform, expected, got)
+		}
+	}
+}
+
+func TestApp_UnmarshalJSON(t *testing.T) {
+	data := []byte(`["389801252", {"id": "447188370", "countries": ["us", "jp"]}]`)
+
+	var apps []App
+	if err := json.Unmarshal(data, &apps); err != nil {
+		t.Fatalf("Failed to unmarshal apps: %v", err)
+	}
+
+	if len(apps) != 2 {
+		t.Fatalf("Expected 2 apps, got %d", len(apps))
+	}
+	if apps[0].ID != "389801252" || len(apps[0].Countries) != 0 {
+		t.Errorf("Expected bare ID to decode without countries, got %+v", apps[0])
+	}
+	if apps[1].ID != "447188370" || !reflect.DeepEqual(apps[1].Countries, []string{"us", "jp"}) {
+		t.Errorf("Expected object form to decode with countries, got %+v", apps[1])
+	}
+}
+
+func TestApp_UnmarshalJSONInvalid(t *testing.T) {
+	var app App
+	if err := json.Unmarshal([]byte(`42`), &app); err == nil {
+		t.Error("Expected error for invalid app definition, got nil")
+	}
+}
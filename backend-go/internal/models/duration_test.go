@@ -0,0 +1,36 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDuration_JSONRoundTrip(t *testing.T) {
+	original := Duration(90 * time.Second)
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != `"1m30s"` {
+		t.Errorf("Expected \"1m30s\", got %s", data)
+	}
+
+	var decoded Duration
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded != original {
+		t.Errorf("Expected %v, got %v", time.Duration(original), time.Duration(decoded))
+	}
+}
+
+func TestDuration_UnmarshalInvalid(t *testing.T) {
+	for _, input := range []string{`"soon"`, `300`, `true`} {
+		var d Duration
+		if err := json.Unmarshal([]byte(input), &d); err == nil {
+			t.Errorf("Expected error for %s, got nil", input)
+		}
+	}
+}
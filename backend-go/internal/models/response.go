@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// States of a DeveloperResponse in App Store Connect
+const (
+	ResponseStatePublished      = "PUBLISHED"
+	ResponseStatePendingPublish = "PENDING_PUBLISH"
+)
+
+// DeveloperResponse is the developer's public reply to a review
+type DeveloperResponse struct {
+	Body           string    `json:"body"`
+	State          string    `json:"state"` // ResponseStatePublished or ResponseStatePendingPublish
+	LastModifiedAt time.Time `json:"last_modified_at"`
+}
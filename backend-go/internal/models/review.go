@@ -6,10 +6,20 @@ import (
 
 type Review struct {
     ID          string    `json:"id"`           // Unique identifier
-    AppID       string    `json:"app_id"`       // iTunes app ID
+    AppID       string    `json:"app_id"`       // iTunes app ID or Android package name
+    Platform    string    `json:"platform"`     // PlatformIOS or PlatformAndroid (empty for reviews stored before Android support)
+    Country     string    `json:"country"`      // Storefront country code (e.g. "us")
     Author      string    `json:"author"`
+    Title       string    `json:"title"`
     Content     string    `json:"content"`
     Rating      int       `json:"rating"`       // Score (1-5)
+    Version     string    `json:"version"`      // App version the review was written for
+    VoteSum     int       `json:"vote_sum"`     // Users who found the review helpful
+    VoteCount   int       `json:"vote_count"`   // Users who voted on the review
+    Link        string    `json:"link"`         // Review page in the store
     SubmittedAt time.Time `json:"submitted_at"`
-    FetchedAt   time.Time `json:"fetched_at"`   // When we fetched it
+    FetchedAt   time.Time `json:"fetched_at"`   // When we first fetched this version of it
+    RemovedAt   *time.Time `json:"removed_at,omitempty"` // When the review disappeared from the store, nil while listed
+    Response    *DeveloperResponse `json:"response,omitempty"` // Developer reply from App Store Connect, nil while unanswered
+    ResponseSyncedAt *time.Time `json:"response_synced_at,omitempty"` // When Response was last set by the App Store Connect sync, nil while it is unknown
 }
\ No newline at end of file
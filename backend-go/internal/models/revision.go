@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// ReviewRevision is one version of the user editable part of a review
+type ReviewRevision struct {
+	Title       string    `json:"title"`
+	Content     string    `json:"content"`
+	Rating      int       `json:"rating"`
+	SubmittedAt time.Time `json:"submitted_at"` // Store timestamp of this version
+	RecordedAt  time.Time `json:"recorded_at"`  // When we fetched this version
+}
+
+// Revision returns the current version of the review, recorded at recordedAt
+func (r Review) Revision(recordedAt time.Time) ReviewRevision {
+	return ReviewRevision{
+		Title:       r.Title,
+		Content:     r.Content,
+		Rating:      r.Rating,
+		SubmittedAt: r.SubmittedAt,
+		RecordedAt:  recordedAt,
+	}
+}
+
+// EditedIn reports whether other is an edit of the review, i.e. its content,
+// title or rating differ. Metadata such as votes may change without an edit.
+func (r Review) EditedIn(other Review) bool {
+	return r.Content != other.Content || r.Title != other.Title || r.Rating != other.Rating
+}
@@ -0,0 +1,156 @@
+package poller
+
+import (
+	"sync"
+	"time"
+
+	"backend/internal/models"
+)
+
+// AdaptiveConfig bounds how far the poller moves an app's interval away from
+// the configured one based on how many new reviews recent polls found. The
+// bounds are relative to each app's own interval, so an app polled every
+// hour and one polled every minute each stay near their configured pace.
+type AdaptiveConfig struct {
+	MaxFactor        float64 // The interval stays within base/MaxFactor and base*MaxFactor, adaptive polling is disabled when zero
+	TargetNewReviews float64 // New reviews per poll the interval is tuned for
+}
+
+// DefaultAdaptiveConfig returns the adaptive polling settings used when none
+// are configured. Without bounds apps keep their configured interval.
+func DefaultAdaptiveConfig() AdaptiveConfig {
+	return AdaptiveConfig{
+		TargetNewReviews: 5,
+	}
+}
+
+// enabled reports whether intervals adapt at all
+func (c AdaptiveConfig) enabled() bool {
+	return c.MaxFactor >= 1 && c.TargetNewReviews > 0
+}
+
+// bounds returns the shortest and longest interval of an app polled every base
+func (c AdaptiveConfig) bounds(base time.Duration) (shortest, longest time.Duration) {
+	return time.Duration(float64(base) / c.MaxFactor), time.Duration(float64(base) * c.MaxFactor)
+}
+
+// velocityWindow is the number of recent polls the review velocity is measured over
+const velocityWindow = 5
+
+// WithAdaptiveInterval lets each app's interval follow its review velocity
+// within config.MaxFactor of its configured interval
+func WithAdaptiveInterval(config AdaptiveConfig) Option {
+	return func(p *Poller) {
+		p.adaptiveCfg = config
+	}
+}
+
+// pollSample is the outcome of one successful poll
+type pollSample struct {
+	newReviews int
+	elapsed    time.Duration // Time since the previous poll
+}
+
+// adaptiveInterval tracks the review velocity of an app and derives its interval
+type adaptiveInterval struct {
+	mu       sync.Mutex
+	config   AdaptiveConfig
+	shortest time.Duration // Bounds derived from the app's configured interval
+	longest  time.Duration
+	current  time.Duration
+	lastPoll time.Time
+	samples  []pollSample
+	now      func() time.Time
+}
+
+func newAdaptiveInterval(config AdaptiveConfig, base time.Duration) *adaptiveInterval {
+	shortest, longest := config.bounds(base)
+	return &adaptiveInterval{
+		config:   config,
+		shortest: shortest,
+		longest:  longest,
+		current:  base,
+		now:      time.Now,
+	}
+}
+
+// interval returns the current interval
+func (a *adaptiveInterval) interval() time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.current
+}
+
+// record adds a successful poll that found newReviews reviews and returns the
+// interval before and after. The interval moves towards one at which a poll
+// finds TargetNewReviews reviews, at most halving or doubling per poll so a
+// single burst does not swing it from one bound to the other.
+func (a *adaptiveInterval) record(newReviews int) (previous, current time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	previous = a.current
+	now := a.now()
+	last := a.lastPoll
+	a.lastPoll = now
+
+	// The first poll covers an unknown period, e.g. the downtime before a restart
+	if last.IsZero() {
+		return previous, previous
+	}
+
+	a.samples = append(a.samples, pollSample{newReviews: newReviews, elapsed: now.Sub(last)})
+	if len(a.samples) > velocityWindow {
+		a.samples = a.samples[len(a.samples)-velocityWindow:]
+	}
+
+	total := 0
+	var span time.Duration
+	for _, sample := range a.samples {
+		total += sample.newReviews
+		span += sample.elapsed
+	}
+
+	next := 2 * previous // Nothing new lately, back off
+	if total > 0 && span > 0 {
+		perSecond := float64(total) / span.Seconds()
+		next = time.Duration(a.config.TargetNewReviews / perSecond * float64(time.Second))
+	}
+	next = min(max(next, previous/2), 2*previous)
+	a.current = min(max(next, a.shortest), a.longest)
+
+	return previous, a.current
+}
+
+// adaptiveFor returns the adaptive interval of an app, creating it on first use
+func (p *Poller) adaptiveFor(app models.App) *adaptiveInterval {
+	p.intervalsMu.Lock()
+	defer p.intervalsMu.Unlock()
+
+	interval, ok := p.intervals[app.ID]
+	if !ok {
+		interval = newAdaptiveInterval(p.adaptiveCfg, p.baseInterval(app))
+		p.intervals[app.ID] = interval
+	}
+	return interval
+}
+
+// resetAdaptive drops the adaptive interval of an app, so it starts over
+// from the app's configured interval
+func (p *Poller) resetAdaptive(appID string) {
+	p.intervalsMu.Lock()
+	defer p.intervalsMu.Unlock()
+	delete(p.intervals, appID)
+}
+
+// adapt feeds a successful poll of an app into its adaptive interval
+func (p *Poller) adapt(app models.App, newReviews int) {
+	if !p.adaptiveCfg.enabled() {
+		return
+	}
+	previous, current := p.adaptiveFor(app).record(newReviews)
+	if current != previous {
+		p.logger.Printf("Poll interval of app %s changed from %v to %v (%d new reviews)",
+			app.ID, previous, current, newReviews)
+	}
+}
@@ -0,0 +1,176 @@
+package poller
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+func newTestAdaptiveInterval(config AdaptiveConfig, base time.Duration) (*adaptiveInterval, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	interval := newAdaptiveInterval(config, base)
+	interval.now = clock.Now
+	return interval, clock
+}
+
+func TestAdaptiveInterval_BacksOffWithoutNewReviews(t *testing.T) {
+	interval, clock := newTestAdaptiveInterval(AdaptiveConfig{MaxFactor: 4, TargetNewReviews: 5}, 10*time.Minute)
+
+	// The first poll only marks the start of the measurement
+	if _, current := interval.record(0); current != 10*time.Minute {
+		t.Fatalf("Expected the first poll to keep the interval, got %v", current)
+	}
+
+	expected := []time.Duration{20 * time.Minute, 40 * time.Minute, 40 * time.Minute}
+	for i, want := range expected {
+		clock.now = clock.now.Add(interval.interval())
+		if _, current := interval.record(0); current != want {
+			t.Errorf("Poll %d: expected interval %v, got %v", i+2, want, current)
+		}
+	}
+}
+
+func TestAdaptiveInterval_ShortensOnBurst(t *testing.T) {
+	interval, clock := newTestAdaptiveInterval(AdaptiveConfig{MaxFactor: 10, TargetNewReviews: 5}, 10*time.Minute)
+	interval.record(0)
+
+	// 50 new reviews in 10 minutes asks for a 1m interval, but the interval only halves per poll
+	clock.now = clock.now.Add(10 * time.Minute)
+	if previous, current := interval.record(50); previous != 10*time.Minute || current != 5*time.Minute {
+		t.Fatalf("Expected interval to halve from 10m to 5m, got %v to %v", previous, current)
+	}
+
+	for i := 0; i < 5; i++ {
+		clock.now = clock.now.Add(interval.interval())
+		interval.record(50)
+	}
+	if current := interval.interval(); current != time.Minute {
+		t.Errorf("Expected interval to settle at the 1m lower bound, got %v", current)
+	}
+}
+
+func TestAdaptiveInterval_SteadyVelocity(t *testing.T) {
+	interval, clock := newTestAdaptiveInterval(AdaptiveConfig{MaxFactor: 4, TargetNewReviews: 5}, 10*time.Minute)
+	interval.record(0)
+
+	// One review every two minutes puts 5 reviews into a 10 minute poll
+	for i := 0; i < velocityWindow; i++ {
+		clock.now = clock.now.Add(10 * time.Minute)
+		if _, current := interval.record(5); current != 10*time.Minute {
+			t.Fatalf("Expected interval to stay at 10m, got %v", current)
+		}
+	}
+}
+
+func TestAdaptiveInterval_BoundsFollowBaseInterval(t *testing.T) {
+	config := AdaptiveConfig{MaxFactor: 4, TargetNewReviews: 5}
+
+	// A quiet app polled every minute must not drift to hours
+	quiet, clock := newTestAdaptiveInterval(config, time.Minute)
+	quiet.record(0)
+	for i := 0; i < 10; i++ {
+		clock.now = clock.now.Add(quiet.interval())
+		quiet.record(0)
+	}
+	if current := quiet.interval(); current != 4*time.Minute {
+		t.Errorf("Expected the 1m app to back off to at most 4m, got %v", current)
+	}
+
+	// A busy app polled every hour must not speed up to minutes
+	busy, clock := newTestAdaptiveInterval(config, time.Hour)
+	busy.record(0)
+	for i := 0; i < 10; i++ {
+		clock.now = clock.now.Add(busy.interval())
+		busy.record(1000)
+	}
+	if current := busy.interval(); current != 15*time.Minute {
+		t.Errorf("Expected the 1h app to speed up to at most 15m, got %v", current)
+	}
+}
+
+func TestPoller_fetchAndStoreCountsNewReviews(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(feedPageJSON([]string{"r1", "r2", "r3"}, "")))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	storage := testutil.NewMockStorage()
+	storage.SaveReviews([]models.Review{{ID: "r3", AppID: "123", Country: "us"}})
+	poller := NewPoller(storage, log.New(io.Discard, "", 0), nil, time.Second)
+
+	result, err := poller.fetchAndStore(models.App{ID: "123"}, "us")
+	if err != nil {
+		t.Fatalf("fetchAndStore failed: %v", err)
+	}
+	if result.Fetched != 3 || result.New != 2 {
+		t.Errorf("Expected 3 fetched and 2 new reviews, got %+v", result)
+	}
+}
+
+func TestPoller_AdaptiveIntervalInStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(feedPageJSON(nil, "")))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	apps := []models.App{{ID: "app1", Interval: models.Duration(10 * time.Minute)}}
+
+	fixed := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), apps, time.Minute)
+	fixed.pollAllAppsConcurrently()
+	fixed.pollAllAppsConcurrently()
+	if interval := fixed.Status()[0].Interval; time.Duration(interval) != 10*time.Minute {
+		t.Errorf("Expected the configured interval without adaptive polling, got %v", time.Duration(interval))
+	}
+
+	adaptive := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), apps, time.Minute,
+		WithAdaptiveInterval(AdaptiveConfig{MaxFactor: 4, TargetNewReviews: 5}))
+	adaptive.pollAllAppsConcurrently()
+	adaptive.pollAllAppsConcurrently()
+	if interval := adaptive.Status()[0].Interval; time.Duration(interval) != 20*time.Minute {
+		t.Errorf("Expected the interval to back off to 20m after a poll without reviews, got %v", time.Duration(interval))
+	}
+}
+
+func TestPoller_AdaptiveBoundsPerApp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(feedPageJSON(nil, "")))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	apps := []models.App{
+		{ID: "fast", Interval: models.Duration(time.Minute)},
+		{ID: "slow", Interval: models.Duration(time.Hour)},
+	}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), apps, time.Minute,
+		WithAdaptiveInterval(AdaptiveConfig{MaxFactor: 2, TargetNewReviews: 5}))
+
+	for i := 0; i < 5; i++ {
+		poller.pollAllAppsConcurrently()
+	}
+
+	// Without new reviews both back off, each only to twice its own interval
+	expected := map[string]time.Duration{"fast": 2 * time.Minute, "slow": 2 * time.Hour}
+	for _, status := range poller.Status() {
+		if time.Duration(status.Interval) != expected[status.AppID] {
+			t.Errorf("Expected app %s to back off to %v, got %v", status.AppID, expected[status.AppID], time.Duration(status.Interval))
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+
+	"backend/internal/httpclient"
+	"backend/internal/models"
+)
+
+// backfillSortOrders are walked by Backfill. The feeds serve at most
+// maxFeedPages pages per sort order, so the helpful reviews reach further back
+// than the recent ones alone.
+var backfillSortOrders = []string{SortMostRecent, SortMostHelpful}
+
+// BackfillResult summarizes the backfill of an app
+type BackfillResult struct {
+	Fetched int // Distinct reviews found in the feeds
+	Added   int // Fetched reviews that were not stored before
+	Parse   ParseStats
+}
+
+// Backfill fetches every page of both sort orders of an iOS app's feeds in
+// the given storefronts and stores the reviews. Unlike a poll it does not stop
+// at reviews already stored. A failed feed does not stop the others; the
+// reviews fetched so far are stored and the failures are returned together.
+// It does not need the poller to be started.
+func (p *Poller) Backfill(app models.App, countries []string) (BackfillResult, error) {
+	if app.StorePlatform() != models.PlatformIOS {
+		return BackfillResult{}, fmt.Errorf("backfill is not supported for platform %q", app.StorePlatform())
+	}
+
+	source := NewITunesSource(p.logger)
+	seen := make(map[string]bool)
+
+	var result BackfillResult
+	var errs []error
+	for _, country := range countries {
+		for _, sortBy := range backfillSortOrders {
+			feed := fmt.Sprintf("%s sorted by %s", feedLabel(app.ID, country), sortBy)
+			p.logger.Printf("Backfilling app %s", feed)
+
+			client := backfillClient{p: p, ctx: httpclient.WithSequence(p.ctx, feed)}
+			reviews, err := source.FetchAllReviews(client, app.ID, country, sortBy, &result.Parse)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("app %s: %w", feed, err))
+			}
+
+			var fresh []models.Review
+			for _, review := range reviews {
+				if seen[review.ID] {
+					continue // Already found in another page or sort order
+				}
+				seen[review.ID] = true
+				fresh = append(fresh, review)
+				if !p.storage.HasReview(review.ID) {
+					result.Added++
+				}
+			}
+			result.Fetched += len(fresh)
+
+			if len(fresh) == 0 {
+				continue
+			}
+			if err := p.storage.SaveReviews(fresh); err != nil {
+				return result, errors.Join(append(errs, fmt.Errorf("failed to store reviews: %w", err))...)
+			}
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// backfillClient sends the requests of a backfill. It shares the poller's
+// retries and rate limit but never records feed validators, so a backfill
+// does not turn the next poll of a feed into a 304.
+type backfillClient struct {
+	p   *Poller
+	ctx context.Context // Makes the requests of a feed one httpclient sequence
+}
+
+func (c backfillClient) Get(url string, header http.Header) (*http.Response, error) {
+	return c.p.get(c.ctx, url, header)
+}
+
+func (c backfillClient) PostForm(url string, data neturl.Values) (*http.Response, error) {
+	return nil, errors.New("backfill does not post forms")
+}
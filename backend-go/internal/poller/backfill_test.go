@@ -0,0 +1,113 @@
+package poller
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+func TestPoller_Backfill(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+
+	// Two pages per sort order; the helpful feed overlaps the recent one
+	// and reaches one review further back
+	pages := map[string][]string{
+		"mostRecent/page=1":  {"r1", "r2"},
+		"mostRecent/page=2":  {"r3", "r4"},
+		"mostHelpful/page=1": {"r4", "r2"},
+		"mostHelpful/page=2": {"r5"},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+
+		// Paths look like /us/rss/customerreviews/id=123/sortBy=mostRecent/page=1/json
+		parts := strings.Split(strings.TrimSuffix(r.URL.Path, "/json"), "/")
+		country := parts[1]
+		key := strings.TrimPrefix(strings.Join(parts[len(parts)-2:], "/"), "sortBy=")
+
+		next := ""
+		if strings.HasSuffix(key, "page=1") {
+			next = strings.Replace(r.URL.Path, "page=1", "page=2", 1)
+		}
+		ids := make([]string, 0, len(pages[key]))
+		for _, id := range pages[key] {
+			ids = append(ids, country+"-"+id)
+		}
+		w.Write([]byte(feedPageJSON(ids, next)))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	storage := testutil.NewMockStorage()
+	storage.SaveReviews([]models.Review{{ID: "us-r1", AppID: "123", Country: "us"}})
+	poller := NewPoller(storage, log.New(io.Discard, "", 0), nil, time.Hour)
+
+	result, err := poller.Backfill(models.App{ID: "123"}, []string{"us", "de"})
+	if err != nil {
+		t.Fatalf("Backfill failed: %v", err)
+	}
+
+	if result.Fetched != 10 || result.Added != 9 {
+		t.Errorf("Expected 10 fetched and 9 added reviews, got %+v", result)
+	}
+	if storage.GetSavedReviewCount() != 10 {
+		t.Errorf("Expected 10 stored reviews, got %d", storage.GetSavedReviewCount())
+	}
+	// Both sort orders are walked to the end even though us-r1 was already stored
+	if len(requested) != 8 {
+		t.Errorf("Expected 2 pages per sort order and storefront, got %v", requested)
+	}
+}
+
+func TestPoller_BackfillKeepsGoingAfterFailedFeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "sortBy=mostHelpful") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(feedPageJSON([]string{fmt.Sprintf("r-%s", strings.Split(r.URL.Path, "/")[1])}, "")))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	storage := testutil.NewMockStorage()
+	poller := NewPoller(storage, log.New(io.Discard, "", 0), nil, time.Hour)
+
+	result, err := poller.Backfill(models.App{ID: "123"}, []string{"us", "de"})
+	if err == nil {
+		t.Fatal("Expected error for the failing feeds, got nil")
+	}
+	if !strings.Contains(err.Error(), "123 (us) sorted by mostHelpful") || !strings.Contains(err.Error(), "123 (de) sorted by mostHelpful") {
+		t.Errorf("Expected both failed feeds in the error, got %v", err)
+	}
+	if result.Added != 2 || storage.GetSavedReviewCount() != 2 {
+		t.Errorf("Expected the reviews of the working feeds to be stored, got %+v", result)
+	}
+}
+
+func TestPoller_BackfillUnsupportedPlatform(t *testing.T) {
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), nil, time.Hour)
+
+	app := models.App{ID: "com.example.app", Platform: models.PlatformAndroid}
+	if _, err := poller.Backfill(app, nil); err == nil {
+		t.Error("Expected error for an Android app, got nil")
+	}
+}
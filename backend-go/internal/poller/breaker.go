@@ -0,0 +1,136 @@
+package poller
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of an app storefront's circuit breaker
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // Polling normally
+	BreakerOpen     BreakerState = "open"      // Polling paused until the cool-down ends
+	BreakerHalfOpen BreakerState = "half-open" // A single trial poll decides whether to close again
+)
+
+// BreakerConfig controls when a storefront's circuit breaker opens and for how long
+type BreakerConfig struct {
+	FailureThreshold int           // Consecutive failed fetches that open the breaker (0 disables it)
+	CoolDown         time.Duration // How long the breaker stays open before a trial poll
+}
+
+// DefaultBreakerConfig returns the circuit breaker settings used when none are configured
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureThreshold: 5,
+		CoolDown:         30 * time.Minute,
+	}
+}
+
+// BreakerStatus is a snapshot of a circuit breaker
+type BreakerStatus struct {
+	State               BreakerState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	OpenedAt            *time.Time   `json:"opened_at,omitempty"`
+	RetryAt             *time.Time   `json:"retry_at,omitempty"` // When the next trial poll is allowed
+}
+
+// circuitBreaker stops polling a storefront that keeps failing and periodically
+// lets a single trial poll through to check whether it recovered
+type circuitBreaker struct {
+	mu       sync.Mutex
+	config   BreakerConfig
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	now      func() time.Time
+}
+
+func newCircuitBreaker(config BreakerConfig) *circuitBreaker {
+	return &circuitBreaker{
+		config: config,
+		state:  BreakerClosed,
+		now:    time.Now,
+	}
+}
+
+// allow reports whether a poll may run. Once the cool-down has passed an
+// open breaker turns half-open and admits exactly one trial poll.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.config.CoolDown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		return true
+	case BreakerHalfOpen:
+		return false // Trial poll already in flight
+	default:
+		return true
+	}
+}
+
+// abortTrial hands back the trial poll admitted by allow when it was cancelled
+// before deciding anything. The breaker is open again with its original
+// openedAt, so the next poll is the trial.
+func (b *circuitBreaker) abortTrial() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerHalfOpen {
+		b.state = BreakerOpen
+	}
+}
+
+// recordSuccess closes the breaker and returns the state it was in before
+func (b *circuitBreaker) recordSuccess() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	previous := b.state
+	b.state = BreakerClosed
+	b.failures = 0
+	return previous
+}
+
+// recordFailure counts a failed poll and reports whether it opened the breaker
+func (b *circuitBreaker) recordFailure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+
+	if b.config.FailureThreshold <= 0 {
+		return false
+	}
+
+	// A failed trial reopens immediately, otherwise wait for the threshold
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= b.config.FailureThreshold) {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+		return true
+	}
+	return false
+}
+
+func (b *circuitBreaker) status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+	}
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		retryAt := b.openedAt.Add(b.config.CoolDown)
+		status.OpenedAt = &openedAt
+		status.RetryAt = &retryAt
+	}
+	return status
+}
@@ -0,0 +1,350 @@
+package poller
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+// fakeClock is a manually advanced clock for circuit breaker tests
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newTestBreaker(config BreakerConfig) (*circuitBreaker, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	breaker := newCircuitBreaker(config)
+	breaker.now = clock.Now
+	return breaker, clock
+}
+
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	breaker, _ := newTestBreaker(BreakerConfig{FailureThreshold: 3, CoolDown: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		if opened := breaker.recordFailure(); opened {
+			t.Fatalf("Breaker opened after %d failures, threshold is 3", i+1)
+		}
+		if !breaker.allow() {
+			t.Fatal("Closed breaker should allow polls")
+		}
+	}
+
+	if opened := breaker.recordFailure(); !opened {
+		t.Fatal("Expected breaker to open on the third failure")
+	}
+	if breaker.allow() {
+		t.Error("Open breaker should not allow polls")
+	}
+	if breaker.status().State != BreakerOpen {
+		t.Errorf("Expected state %s, got %s", BreakerOpen, breaker.status().State)
+	}
+}
+
+func TestCircuitBreaker_SuccessResetsFailures(t *testing.T) {
+	breaker, _ := newTestBreaker(BreakerConfig{FailureThreshold: 2, CoolDown: time.Minute})
+
+	breaker.recordFailure()
+	breaker.recordSuccess()
+
+	if opened := breaker.recordFailure(); opened {
+		t.Error("Failures before a success should not count towards the threshold")
+	}
+}
+
+func TestCircuitBreaker_HalfOpenAfterCoolDown(t *testing.T) {
+	breaker, clock := newTestBreaker(BreakerConfig{FailureThreshold: 1, CoolDown: time.Minute})
+
+	breaker.recordFailure()
+
+	clock.now = clock.now.Add(59 * time.Second)
+	if breaker.allow() {
+		t.Fatal("Breaker should stay open during the cool-down")
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	if !breaker.allow() {
+		t.Fatal("Breaker should allow a trial poll after the cool-down")
+	}
+	if breaker.status().State != BreakerHalfOpen {
+		t.Errorf("Expected state %s, got %s", BreakerHalfOpen, breaker.status().State)
+	}
+	if breaker.allow() {
+		t.Error("Half-open breaker should only allow a single trial poll")
+	}
+
+	if previous := breaker.recordSuccess(); previous != BreakerHalfOpen {
+		t.Errorf("Expected previous state %s, got %s", BreakerHalfOpen, previous)
+	}
+	if breaker.status().State != BreakerClosed || !breaker.allow() {
+		t.Error("Successful trial should close the breaker")
+	}
+}
+
+func TestCircuitBreaker_FailedTrialReopens(t *testing.T) {
+	breaker, clock := newTestBreaker(BreakerConfig{FailureThreshold: 3, CoolDown: time.Minute})
+
+	for i := 0; i < 3; i++ {
+		breaker.recordFailure()
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	breaker.allow()
+
+	if opened := breaker.recordFailure(); !opened {
+		t.Fatal("Failed trial poll should reopen the breaker")
+	}
+
+	status := breaker.status()
+	if status.State != BreakerOpen {
+		t.Errorf("Expected state %s, got %s", BreakerOpen, status.State)
+	}
+	if !status.RetryAt.Equal(clock.now.Add(time.Minute)) {
+		t.Errorf("Expected a fresh cool-down until %v, got %v", clock.now.Add(time.Minute), status.RetryAt)
+	}
+}
+
+func TestCircuitBreaker_AbortedTrialReopens(t *testing.T) {
+	breaker, clock := newTestBreaker(BreakerConfig{FailureThreshold: 1, CoolDown: time.Minute})
+	breaker.recordFailure()
+	openedAt := clock.now
+
+	clock.now = clock.now.Add(time.Minute)
+	if !breaker.allow() {
+		t.Fatal("Expected a trial poll after the cool-down")
+	}
+	breaker.abortTrial()
+
+	status := breaker.status()
+	if status.State != BreakerOpen || !status.OpenedAt.Equal(openedAt) {
+		t.Errorf("Expected the breaker open since %v again, got %+v", openedAt, status)
+	}
+	if !breaker.allow() {
+		t.Error("Expected the next poll to be the trial")
+	}
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	breaker, _ := newTestBreaker(BreakerConfig{FailureThreshold: 0})
+
+	for i := 0; i < 10; i++ {
+		if breaker.recordFailure() {
+			t.Fatal("Disabled breaker should never open")
+		}
+	}
+	if !breaker.allow() {
+		t.Error("Disabled breaker should always allow polls")
+	}
+	if breaker.status().ConsecutiveFailures != 10 {
+		t.Errorf("Expected 10 consecutive failures, got %d", breaker.status().ConsecutiveFailures)
+	}
+}
+
+func TestPoller_BreakerStopsPollingFailingApp(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	var buf testutil.SafeBuffer
+	apps := []models.App{{ID: "dead", Countries: []string{"us", "de"}}}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(&buf, "", 0), apps, time.Second,
+		WithCircuitBreaker(BreakerConfig{FailureThreshold: 2, CoolDown: time.Hour}))
+
+	for i := 0; i < 5; i++ {
+		poller.pollAllAppsConcurrently()
+	}
+
+	// Two failed polls of two storefronts each, then the breaker stays open
+	if requests.Load() != 4 {
+		t.Errorf("Expected 4 requests before the breaker opened, got %d", requests.Load())
+	}
+
+	logOutput := buf.String()
+	for _, feed := range []string{"dead (us)", "dead (de)"} {
+		if strings.Count(logOutput, "Circuit breaker opened for app "+feed) != 1 {
+			t.Errorf("Expected one breaker open message for %s, got: %s", feed, logOutput)
+		}
+	}
+	if strings.Count(logOutput, "Error polling app dead") != 4 {
+		t.Errorf("Expected errors to stop once the breaker opened, got: %s", logOutput)
+	}
+
+	status := poller.Status()
+	if len(status) != 1 || status[0].AppID != "dead" {
+		t.Fatalf("Expected status for app 'dead', got %+v", status)
+	}
+	for _, breaker := range status[0].Breakers {
+		if breaker.State != BreakerOpen {
+			t.Errorf("Expected breaker state %s for %s, got %s", BreakerOpen, breaker.Country, breaker.State)
+		}
+	}
+
+	// With every storefront paused the whole app is skipped
+	if result := poller.pollMerged(apps[0]); !result.Skipped {
+		t.Errorf("Expected the app to be skipped, got %+v", result)
+	}
+}
+
+func TestPoller_CancelledTrialPollIsRetriedAfterRestart(t *testing.T) {
+	const (
+		failing = iota
+		stalling
+		healthy
+	)
+	var mode, healthyRequests atomic.Int32
+	stalled := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch mode.Load() {
+		case failing:
+			w.WriteHeader(http.StatusForbidden)
+		case stalling:
+			stalled <- struct{}{}
+			<-r.Context().Done()
+		default:
+			healthyRequests.Add(1)
+			w.Write([]byte(feedPageJSON(nil, "")))
+		}
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	apps := []models.App{{ID: "123", Interval: models.Duration(time.Hour)}}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), apps, time.Hour,
+		WithJitter(0), WithCircuitBreaker(BreakerConfig{FailureThreshold: 1, CoolDown: 20 * time.Millisecond}))
+
+	poller.pollAllAppsConcurrently()
+	time.Sleep(30 * time.Millisecond) // Wait out the cool-down
+
+	// Stopping cancels the trial poll
+	mode.Store(stalling)
+	poller.Start()
+	select {
+	case <-stalled:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Trial poll did not start")
+	}
+	poller.Stop()
+
+	if state := poller.Status()[0].Breakers[0].State; state != BreakerOpen {
+		t.Fatalf("Expected the cancelled trial to leave the breaker open, got %s", state)
+	}
+
+	// After a restart the storefront gets its trial poll
+	mode.Store(healthy)
+	poller.Start()
+	deadline := time.Now().Add(2 * time.Second)
+	for healthyRequests.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	poller.Stop()
+
+	if healthyRequests.Load() == 0 {
+		t.Error("Expected the storefront to be polled again after the restart")
+	}
+	if state := poller.Status()[0].Breakers[0].State; state != BreakerClosed {
+		t.Errorf("Expected the successful trial to close the breaker, got %s", state)
+	}
+}
+
+func TestPoller_BreakerPausesFailingStorefront(t *testing.T) {
+	var jpRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/jp/") {
+			jpRequests.Add(1)
+			w.WriteHeader(http.StatusForbidden) // Region-locked
+			return
+		}
+		w.Write([]byte(feedPageJSON(nil, "")))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	apps := []models.App{{ID: "app1", Countries: []string{"us", "jp"}}}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), apps, time.Second,
+		WithCircuitBreaker(BreakerConfig{FailureThreshold: 2, CoolDown: time.Hour}))
+
+	for i := 0; i < 4; i++ {
+		if result := poller.pollMerged(apps[0]); result.Skipped || !result.Succeeded {
+			t.Fatalf("Expected the app to keep being polled, got %+v", result)
+		}
+	}
+
+	if jpRequests.Load() != 2 {
+		t.Errorf("Expected the failing storefront to be paused after 2 requests, got %d", jpRequests.Load())
+	}
+
+	status := poller.Status()[0]
+	states := make(map[string]BreakerState)
+	for _, breaker := range status.Breakers {
+		states[breaker.Country] = breaker.State
+	}
+	if states["us"] != BreakerClosed || states["jp"] != BreakerOpen {
+		t.Errorf("Expected us closed and jp open, got %v", states)
+	}
+	if status.ConsecutiveFailures != 0 {
+		t.Errorf("Expected polls with a fetched storefront not to count as failed, got %d", status.ConsecutiveFailures)
+	}
+}
+
+func TestPoller_FetchesStorefrontsInParallel(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.Write([]byte(feedPageJSON(nil, "")))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	apps := []models.App{{ID: "app1", Countries: []string{"us", "de", "fr", "jp", "gb"}}}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), apps, time.Second,
+		WithMaxConcurrency(3), WithRateLimit(RateLimitConfig{}))
+
+	poller.pollAllAppsConcurrently()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if peak > 3 {
+		t.Errorf("Expected at most 3 concurrent storefront fetches, got %d", peak)
+	}
+	if peak < 2 {
+		t.Errorf("Expected the storefronts to be fetched in parallel, peak was %d", peak)
+	}
+}
@@ -0,0 +1,173 @@
+package poller
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// driftMinParseRatio is the share of review entries that must parse for a
+// feed to look like it used to. Below it the feed has drifted.
+const driftMinParseRatio = 0.5
+
+// driftEmptyPolls is the number of polls in a row without a single entry
+// after which a feed that used to list reviews has drifted, e.g. because the
+// store renamed the entry field and the feed decodes as empty
+const driftEmptyPolls = 3
+
+// DriftStatus describes a feed whose entries no longer look like they used to,
+// e.g. because the store changed the feed format
+type DriftStatus struct {
+	AppID         string    `json:"app_id"`
+	Country       string    `json:"country,omitempty"`
+	Since         time.Time `json:"since"`
+	Reason        string    `json:"reason"`
+	ParseRatio    float64   `json:"parse_ratio"`              // Share of review entries parsed in the last poll
+	MissingFields []string  `json:"missing_fields,omitempty"` // Fields every review entry used to have
+}
+
+// feedSchema is what a feed looked like in its last healthy poll
+type feedSchema struct {
+	fields     map[string]bool // Fields every review entry had, nil until learned
+	listed     bool            // The feed had review entries before
+	emptyPolls int             // Polls in a row without any entry since then
+	drift      *DriftStatus
+}
+
+// schemaMonitor watches the parse stats of every feed for schema drift
+type schemaMonitor struct {
+	mu    sync.Mutex
+	feeds map[string]map[string]*feedSchema // By app ID and country
+}
+
+// forget drops the feeds of an app
+func (m *schemaMonitor) forget(appID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.feeds, appID)
+}
+
+// observe checks the parse stats of a fetched feed against what the feed
+// looked like before. It returns the drift of the feed, nil when it is
+// healthy, and whether that changed with this fetch. A feed without entries
+// drifted once it stayed empty for driftEmptyPolls polls after it had
+// entries before.
+func (m *schemaMonitor) observe(appID, country string, stats ParseStats, now time.Time) (*DriftStatus, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	feed := m.feed(appID, country)
+	entries := stats.Seen - stats.SkipReasons[skipMetadataEntry]
+	if entries == 0 {
+		if !feed.listed {
+			return feed.current(), false // Nothing to judge the feed by
+		}
+		feed.emptyPolls++
+		if feed.emptyPolls < driftEmptyPolls {
+			return feed.current(), false
+		}
+		return feed.flag(appID, country, fmt.Sprintf("no entries in %d polls in a row", feed.emptyPolls), 0, nil, now)
+	}
+	feed.listed = true
+	feed.emptyPolls = 0
+
+	ratio := float64(stats.Parsed) / float64(entries)
+	var missing []string
+	if stats.fields != nil {
+		for field := range feed.fields {
+			if stats.fields[field] == 0 {
+				missing = append(missing, field)
+			}
+		}
+		slices.Sort(missing)
+	}
+
+	var reason string
+	switch {
+	case ratio < driftMinParseRatio:
+		reason = fmt.Sprintf("only %d of %d entries parsed", stats.Parsed, entries)
+	case len(missing) > 0:
+		reason = "fields missing from all entries: " + strings.Join(missing, ", ")
+	}
+
+	if reason == "" {
+		if stats.fields != nil {
+			feed.fields = make(map[string]bool)
+			for field, count := range stats.fields {
+				if count == stats.reviewEntries {
+					feed.fields[field] = true
+				}
+			}
+		}
+		wasDrifting := feed.drift != nil
+		feed.drift = nil
+		return nil, wasDrifting
+	}
+
+	return feed.flag(appID, country, reason, ratio, missing, now)
+}
+
+// undecodable flags a feed whose response could not be decoded at all
+func (m *schemaMonitor) undecodable(appID, country string, err error, now time.Time) (*DriftStatus, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.feed(appID, country).flag(appID, country, "feed could not be decoded: "+err.Error(), 0, nil, now)
+}
+
+// feed returns the schema of a feed, creating it on first use. The caller
+// must hold m.mu.
+func (m *schemaMonitor) feed(appID, country string) *feedSchema {
+	if m.feeds[appID] == nil {
+		m.feeds[appID] = make(map[string]*feedSchema)
+	}
+	feed, ok := m.feeds[appID][country]
+	if !ok {
+		feed = &feedSchema{}
+		m.feeds[appID][country] = feed
+	}
+	return feed
+}
+
+// flag marks the feed as drifted for reason and returns a copy of the drift
+// and whether the feed was healthy before
+func (f *feedSchema) flag(appID, country, reason string, ratio float64, missing []string, now time.Time) (*DriftStatus, bool) {
+	changed := f.drift == nil
+	if changed {
+		f.drift = &DriftStatus{AppID: appID, Country: country, Since: now}
+	}
+	f.drift.Reason = reason
+	f.drift.ParseRatio = ratio
+	f.drift.MissingFields = missing
+
+	drift := *f.drift
+	return &drift, changed
+}
+
+// current returns a copy of the drift of the feed, nil when it is healthy
+func (f *feedSchema) current() *DriftStatus {
+	if f.drift == nil {
+		return nil
+	}
+	drift := *f.drift
+	return &drift
+}
+
+// drifts returns the feeds of an app that drifted, ordered by country
+func (m *schemaMonitor) drifts(appID string) []DriftStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var drifts []DriftStatus
+	for _, feed := range m.feeds[appID] {
+		if feed.drift != nil {
+			drifts = append(drifts, *feed.drift)
+		}
+	}
+	slices.SortFunc(drifts, func(a, b DriftStatus) int {
+		return strings.Compare(a.Country, b.Country)
+	})
+	return drifts
+}
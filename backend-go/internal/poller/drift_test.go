@@ -0,0 +1,202 @@
+package poller
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+// reviewFields are the fields of a review entry in the iTunes feed
+var reviewFields = []string{"author", "content", "id", "im:rating", "im:version", "updated"}
+
+// newParseStats returns the stats of a page with parsed and skipped review
+// entries, all having the given fields
+func newParseStats(parsed, skipped int, fields []string) ParseStats {
+	var stats ParseStats
+	for range parsed {
+		stats.observeFields(fields)
+		stats.parsed()
+	}
+	for range skipped {
+		stats.observeFields(fields)
+		stats.skip(skipInvalidRating)
+	}
+	return stats
+}
+
+func newSchemaMonitor() *schemaMonitor {
+	return &schemaMonitor{feeds: make(map[string]map[string]*feedSchema)}
+}
+
+func TestSchemaMonitor_ParseRatio(t *testing.T) {
+	monitor := newSchemaMonitor()
+	start := time.Date(2025, 9, 28, 12, 0, 0, 0, time.UTC)
+
+	if drift, changed := monitor.observe("123", "us", newParseStats(50, 0, reviewFields), start); drift != nil || changed {
+		t.Fatalf("Expected a healthy feed, got %+v", drift)
+	}
+
+	drift, changed := monitor.observe("123", "us", newParseStats(2, 48, reviewFields), start.Add(time.Hour))
+	if drift == nil || !changed {
+		t.Fatal("Expected drift when entries stop parsing")
+	}
+	if drift.Reason != "only 2 of 50 entries parsed" || drift.ParseRatio != 0.04 || !drift.Since.Equal(start.Add(time.Hour)) {
+		t.Errorf("Unexpected drift %+v", drift)
+	}
+
+	// Still drifting: reported, but not as a change, and since the first poll
+	drift, changed = monitor.observe("123", "us", newParseStats(0, 50, reviewFields), start.Add(2*time.Hour))
+	if drift == nil || changed || !drift.Since.Equal(start.Add(time.Hour)) {
+		t.Errorf("Expected ongoing drift since the first bad poll, got %+v (changed %v)", drift, changed)
+	}
+	if drifts := monitor.drifts("123"); len(drifts) != 1 || drifts[0].Reason != "only 0 of 50 entries parsed" {
+		t.Errorf("Expected the latest drift of app 123, got %+v", drifts)
+	}
+
+	if drift, changed := monitor.observe("123", "us", newParseStats(50, 0, reviewFields), start.Add(3*time.Hour)); drift != nil || !changed {
+		t.Errorf("Expected the drift to clear, got %+v (changed %v)", drift, changed)
+	}
+	if drifts := monitor.drifts("123"); len(drifts) != 0 {
+		t.Errorf("Expected no drift after recovery, got %+v", drifts)
+	}
+}
+
+func TestSchemaMonitor_MissingFields(t *testing.T) {
+	monitor := newSchemaMonitor()
+	now := time.Now()
+
+	monitor.observe("123", "us", newParseStats(50, 0, reviewFields), now)
+
+	// Entries still parse, but the version is gone from every one of them
+	withoutVersion := []string{"author", "content", "id", "im:rating", "updated"}
+	drift, changed := monitor.observe("123", "us", newParseStats(50, 0, withoutVersion), now)
+	if drift == nil || !changed {
+		t.Fatal("Expected drift when an expected field disappears")
+	}
+	if len(drift.MissingFields) != 1 || drift.MissingFields[0] != "im:version" || drift.ParseRatio != 1 {
+		t.Errorf("Expected im:version to be missing, got %+v", drift)
+	}
+
+	// Other storefronts of the app are judged on their own
+	if drift, _ := monitor.observe("123", "de", newParseStats(50, 0, withoutVersion), now); drift != nil {
+		t.Errorf("Expected the de feed to be healthy, got %+v", drift)
+	}
+}
+
+func TestSchemaMonitor_IgnoresFeedsWithoutReviews(t *testing.T) {
+	monitor := newSchemaMonitor()
+
+	var metadataOnly ParseStats
+	metadataOnly.skip(skipMetadataEntry)
+	if drift, changed := monitor.observe("123", "us", metadataOnly, time.Now()); drift != nil || changed {
+		t.Errorf("Expected no judgement without review entries, got %+v", drift)
+	}
+	if drift, changed := monitor.observe("123", "us", ParseStats{}, time.Now()); drift != nil || changed {
+		t.Errorf("Expected no judgement for an empty feed, got %+v", drift)
+	}
+}
+
+func TestSchemaMonitor_FeedTurnsEmpty(t *testing.T) {
+	monitor := newSchemaMonitor()
+	start := time.Date(2025, 9, 28, 12, 0, 0, 0, time.UTC)
+
+	monitor.observe("123", "us", newParseStats(50, 0, reviewFields), start)
+
+	// A renamed entry field decodes as a feed without entries
+	for i := 1; i < driftEmptyPolls; i++ {
+		if drift, changed := monitor.observe("123", "us", ParseStats{}, start.Add(time.Duration(i)*time.Hour)); drift != nil || changed {
+			t.Fatalf("Poll %d: expected no drift before %d empty polls, got %+v", i, driftEmptyPolls, drift)
+		}
+	}
+	drift, changed := monitor.observe("123", "us", ParseStats{}, start.Add(driftEmptyPolls*time.Hour))
+	if drift == nil || !changed {
+		t.Fatalf("Expected drift after %d empty polls", driftEmptyPolls)
+	}
+	if drift.Reason != fmt.Sprintf("no entries in %d polls in a row", driftEmptyPolls) {
+		t.Errorf("Unexpected reason %q", drift.Reason)
+	}
+
+	if drift, changed := monitor.observe("123", "us", newParseStats(50, 0, reviewFields), start.Add(10*time.Hour)); drift != nil || !changed {
+		t.Errorf("Expected the drift to clear once entries are back, got %+v", drift)
+	}
+}
+
+func TestPoller_SchemaDriftOnUndecodableFeed(t *testing.T) {
+	var changed atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if changed.Load() {
+			// The store turned the entries into a string
+			w.Write([]byte(`{"feed":{"entry":"moved","link":[]}}`))
+			return
+		}
+		w.Write([]byte(feedPageJSON([]string{"r1", "r2"}, "")))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	var logs testutil.SafeBuffer
+	poller := NewPoller(testutil.NewMockStorage(), log.New(&logs, "", 0), []models.App{{ID: "123"}}, time.Hour)
+
+	poller.pollAllAppsConcurrently()
+	changed.Store(true)
+	poller.pollAllAppsConcurrently()
+
+	drift := poller.Status()[0].SchemaDrift
+	if len(drift) != 1 || !strings.HasPrefix(drift[0].Reason, "feed could not be decoded") {
+		t.Fatalf("Expected drift of the undecodable feed, got %+v", drift)
+	}
+	if !strings.Contains(logs.String(), "Schema drift detected for app 123 (us): feed could not be decoded") {
+		t.Errorf("Expected the drift to be logged, got: %s", logs.String())
+	}
+}
+
+func TestPoller_SchemaDriftInStatus(t *testing.T) {
+	var changed atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := feedPageJSON([]string{"r1", "r2", "r3"}, "")
+		if changed.Load() {
+			// The store renamed the rating field
+			page = strings.ReplaceAll(page, `"im:rating"`, `"im:score"`)
+		}
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	var logs testutil.SafeBuffer
+	apps := []models.App{{ID: "123"}}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(&logs, "", 0), apps, time.Hour)
+
+	poller.pollAllAppsConcurrently()
+	if drift := poller.Status()[0].SchemaDrift; len(drift) != 0 {
+		t.Fatalf("Expected no drift for the usual feed, got %+v", drift)
+	}
+
+	changed.Store(true)
+	poller.pollAllAppsConcurrently()
+
+	drift := poller.Status()[0].SchemaDrift
+	if len(drift) != 1 || drift[0].Country != "us" || drift[0].ParseRatio != 0 {
+		t.Fatalf("Expected drift of the us feed, got %+v", drift)
+	}
+	if len(drift[0].MissingFields) != 1 || drift[0].MissingFields[0] != "im:rating" {
+		t.Errorf("Expected im:rating to be missing, got %+v", drift[0].MissingFields)
+	}
+	if !strings.Contains(logs.String(), "Schema drift detected for app 123 (us): only 0 of 3 entries parsed") {
+		t.Errorf("Expected the drift to be logged, got: %s", logs.String())
+	}
+}
@@ -0,0 +1,229 @@
+package poller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/httpclient"
+	"backend/internal/models"
+)
+
+// DefaultGooglePlayBaseURL is the host of the Google Play Developer API
+const DefaultGooglePlayBaseURL = "https://androidpublisher.googleapis.com"
+
+// maxGooglePlayPages bounds how many review pages are walked per fetch
+const maxGooglePlayPages = 10
+
+// googlePlayPageSize is the number of reviews requested per page (the API maximum)
+const googlePlayPageSize = 100
+
+// GooglePlayConfig configures the Google Play Developer API review source
+type GooglePlayConfig struct {
+	ServiceAccountFile string // JSON key of a service account with access to the apps
+	BaseURL            string // API host, DefaultGooglePlayBaseURL when empty
+	TokenURL           string // OAuth token endpoint, the key's token_uri when empty
+}
+
+// GooglePlaySource reads Android app reviews from the Google Play Developer
+// API, authenticating as a service account
+type GooglePlaySource struct {
+	baseURL string
+	tokens  *serviceAccountTokens
+	logger  *log.Logger
+}
+
+// NewGooglePlaySource loads the service account key named in config
+func NewGooglePlaySource(config GooglePlayConfig, logger *log.Logger) (*GooglePlaySource, error) {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	key, privateKey, err := loadServiceAccountKey(config.ServiceAccountFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenURL := config.TokenURL
+	if tokenURL == "" {
+		tokenURL = key.TokenURI
+	}
+	if tokenURL == "" {
+		tokenURL = defaultGoogleTokenURL
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultGooglePlayBaseURL
+	}
+
+	return &GooglePlaySource{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		tokens: &serviceAccountTokens{
+			key:        key,
+			privateKey: privateKey,
+			tokenURL:   tokenURL,
+			now:        time.Now,
+		},
+		logger: logger,
+	}, nil
+}
+
+func (s *GooglePlaySource) Platform() string {
+	return models.PlatformAndroid
+}
+
+// recentOnly reports that the API only serves the last week of reviews, so
+// the feed of a quiet app is empty
+func (s *GooglePlaySource) recentOnly() bool {
+	return true
+}
+
+// FetchReviews walks the reviews of an Android app newest first until it
+// reaches reviews we already have. The API only serves reviews from the
+// last week, so older reviews are out of reach.
+func (s *GooglePlaySource) FetchReviews(client HTTPClient, req FetchRequest) ([]models.Review, error) {
+	stats := req.Stats
+	if stats == nil {
+		stats = &ParseStats{}
+	}
+
+	var reviews []models.Review
+	pageToken := ""
+
+	for page := 1; page <= maxGooglePlayPages; page++ {
+		pageReviews, nextToken, err := s.fetchPage(client, req.App.ID, pageToken, stats)
+		if err != nil {
+			return reviews, fmt.Errorf("failed to fetch page %d: %w", page, err)
+		}
+
+		reviews = append(reviews, pageReviews...)
+
+		if len(pageReviews) == 0 || nextToken == "" || containsSeen(pageReviews, req.Seen) {
+			break
+		}
+		pageToken = nextToken
+	}
+
+	return reviews, nil
+}
+
+// googlePlayReviewsResponse is the body of reviews.list
+type googlePlayReviewsResponse struct {
+	Reviews []struct {
+		ReviewID   string `json:"reviewId"`
+		AuthorName string `json:"authorName"`
+		Comments   []struct {
+			UserComment *struct {
+				Text           string `json:"text"`
+				StarRating     int    `json:"starRating"`
+				AppVersionName string `json:"appVersionName"`
+				LastModified   struct {
+					Seconds string `json:"seconds"` // int64 encoded as a JSON string
+					Nanos   int    `json:"nanos"`
+				} `json:"lastModified"`
+			} `json:"userComment"`
+		} `json:"comments"`
+	} `json:"reviews"`
+	TokenPagination struct {
+		NextPageToken string `json:"nextPageToken"`
+	} `json:"tokenPagination"`
+}
+
+// fetchPage fetches one page of reviews and returns the token of the next page
+func (s *GooglePlaySource) fetchPage(client HTTPClient, packageName, pageToken string, stats *ParseStats) ([]models.Review, string, error) {
+	token, err := s.tokens.accessToken(client)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := neturl.Values{"maxResults": {strconv.Itoa(googlePlayPageSize)}}
+	if pageToken != "" {
+		query.Set("token", pageToken)
+	}
+	url := fmt.Sprintf("%s/androidpublisher/v3/applications/%s/reviews?%s",
+		s.baseURL, neturl.PathEscape(packageName), query.Encode())
+
+	resp, err := client.Get(url, http.Header{"Authorization": {"Bearer " + token}})
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnauthorized {
+		// The token was revoked or rotated before it expired, retry once with a new one
+		s.tokens.invalidate(token)
+		if token, err = s.tokens.accessToken(client); err != nil {
+			return nil, "", err
+		}
+		resp, err = client.Get(url, http.Header{"Authorization": {"Bearer " + token}})
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	var body googlePlayReviewsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, "", feedDecodeError(resp, "Google Play reviews", err)
+	}
+
+	return s.parsePage(body, packageName, time.Now(), stats), body.TokenPagination.NextPageToken, nil
+}
+
+// parseRecording parses a recorded page of reviews.list
+func (s *GooglePlaySource) parseRecording(recording httpclient.Recording, stats *ParseStats) ([]models.Review, bool, error) {
+	url, err := neturl.Parse(recording.URL)
+	if err != nil {
+		return nil, false, nil
+	}
+	_, path, found := strings.Cut(url.Path, "/androidpublisher/v3/applications/")
+	packageName, isReviews := strings.CutSuffix(path, "/reviews")
+	if !found || !isReviews || packageName == "" {
+		return nil, false, nil
+	}
+
+	var body googlePlayReviewsResponse
+	if err := json.Unmarshal([]byte(recording.Body), &body); err != nil {
+		return nil, true, fmt.Errorf("failed to decode Google Play reviews: %w", &decodeError{err})
+	}
+	return s.parsePage(body, packageName, recording.RecordedAt, stats), true, nil
+}
+
+// parsePage converts a page of reviews fetched at fetchedAt. Reviews that
+// cannot be parsed are skipped and counted in stats.
+func (s *GooglePlaySource) parsePage(body googlePlayReviewsResponse, packageName string, fetchedAt time.Time, stats *ParseStats) []models.Review {
+	reviews := make([]models.Review, 0, len(body.Reviews))
+	for _, entry := range body.Reviews {
+		// The first comment is the user's, developer replies follow
+		if len(entry.Comments) == 0 || entry.Comments[0].UserComment == nil {
+			s.logger.Printf("Warning: skipping Google Play review %s without a user comment", entry.ReviewID)
+			stats.skip(skipNoUserComment)
+			continue
+		}
+		comment := entry.Comments[0].UserComment
+
+		seconds, err := strconv.ParseInt(comment.LastModified.Seconds, 10, 64)
+		if err != nil {
+			s.logger.Printf("Warning: skipping Google Play review %s with invalid timestamp: %v", entry.ReviewID, err)
+			stats.skip(skipInvalidTimestamp)
+			continue
+		}
+		stats.parsed()
+
+		reviews = append(reviews, models.Review{
+			ID:          entry.ReviewID,
+			AppID:       packageName,
+			Platform:    models.PlatformAndroid,
+			Author:      entry.AuthorName,
+			Content:     strings.TrimSpace(comment.Text),
+			Rating:      comment.StarRating,
+			Version:     comment.AppVersionName,
+			SubmittedAt: time.Unix(seconds, int64(comment.LastModified.Nanos)).UTC(),
+			FetchedAt:   fetchedAt,
+		})
+	}
+	return reviews
+}
@@ -0,0 +1,186 @@
+package poller
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// googlePlayScope is the OAuth scope granting access to the Google Play Developer API
+const googlePlayScope = "https://www.googleapis.com/auth/androidpublisher"
+
+// defaultGoogleTokenURL is used when the service account key does not name a token_uri
+const defaultGoogleTokenURL = "https://oauth2.googleapis.com/token"
+
+// serviceAccountKey is the subset of a Google service account JSON key we need
+type serviceAccountKey struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"` // PEM encoded PKCS#8 (or PKCS#1) RSA key
+	TokenURI     string `json:"token_uri"`
+}
+
+// loadServiceAccountKey reads a service account JSON key file and parses its RSA key
+func loadServiceAccountKey(path string) (*serviceAccountKey, *rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read service account key: %w", err)
+	}
+
+	var key serviceAccountKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode service account key: %w", err)
+	}
+	if key.ClientEmail == "" {
+		return nil, nil, errors.New("service account key has no client_email")
+	}
+
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return nil, nil, errors.New("service account key has no PEM encoded private_key")
+	}
+
+	var privateKey *rsa.PrivateKey
+	if parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return nil, nil, errors.New("service account private_key is not an RSA key")
+		}
+		privateKey = rsaKey
+	} else if rsaKey, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		privateKey = rsaKey
+	} else {
+		return nil, nil, fmt.Errorf("failed to parse service account private_key: %w", err)
+	}
+
+	return &key, privateKey, nil
+}
+
+// serviceAccountTokens exchanges signed JWT assertions for OAuth access tokens
+// (the two-legged service account flow) and caches them until shortly before
+// they expire
+type serviceAccountTokens struct {
+	key        *serviceAccountKey
+	privateKey *rsa.PrivateKey
+	tokenURL   string
+	now        func() time.Time
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// tokenExpiryMargin renews access tokens this long before they expire
+const tokenExpiryMargin = time.Minute
+
+// accessToken returns a valid access token, requesting a new one when needed
+func (t *serviceAccountTokens) accessToken(client HTTPClient) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	if t.token != "" && now.Before(t.expiry.Add(-tokenExpiryMargin)) {
+		return t.token, nil
+	}
+
+	assertion, err := t.assertion(now)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.PostForm(t.tokenURL, neturl.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to request access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"` // Seconds
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		if resp.Request != nil && resp.Request.Context().Err() != nil {
+			return "", fmt.Errorf("%w: %v", ErrCancelled, err)
+		}
+		return "", fmt.Errorf("failed to decode access token: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("token response has no access_token")
+	}
+
+	t.token = token.AccessToken
+	t.expiry = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+	return t.token, nil
+}
+
+// invalidate drops the cached access token if it is still token, e.g. after
+// the API rejected it, so the next accessToken call requests a new one
+func (t *serviceAccountTokens) invalidate(token string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token == token {
+		t.token = ""
+	}
+}
+
+// assertion builds the RS256 signed JWT identifying the service account
+func (t *serviceAccountTokens) assertion(now time.Time) (string, error) {
+	header := map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+	}
+	if t.key.PrivateKeyID != "" {
+		header["kid"] = t.key.PrivateKeyID
+	}
+	claims := map[string]any{
+		"iss":   t.key.ClientEmail,
+		"scope": googlePlayScope,
+		"aud":   t.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}
+
+	signingInput, err := jwtSigningInput(header, claims)
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, t.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// jwtSigningInput encodes the JWT header and claims as "header.claims"
+func jwtSigningInput(header map[string]string, claims map[string]any) (string, error) {
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT header: %w", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JWT claims: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(headerJSON) + "." +
+		base64.RawURLEncoding.EncodeToString(claimsJSON), nil
+}
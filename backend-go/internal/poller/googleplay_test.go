@@ -0,0 +1,293 @@
+package poller
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+// fakeGooglePlay serves the OAuth token endpoint and the reviews.list API.
+// Reviews are served newest first, pageSize per page.
+type fakeGooglePlay struct {
+	*httptest.Server
+	t         *testing.T
+	publicKey *rsa.PublicKey
+	reviewIDs []string
+	pageSize  int
+
+	mu            sync.Mutex
+	tokenRequests int
+	pageRequests  int
+}
+
+func newFakeGooglePlay(t *testing.T, publicKey *rsa.PublicKey, reviewIDs []string, pageSize int) *fakeGooglePlay {
+	f := &fakeGooglePlay{t: t, publicKey: publicKey, reviewIDs: reviewIDs, pageSize: pageSize}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", f.handleToken)
+	mux.HandleFunc("GET /androidpublisher/v3/applications/{package}/reviews", f.handleReviews)
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeGooglePlay) handleToken(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.tokenRequests++
+	f.mu.Unlock()
+
+	if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+		f.t.Errorf("Unexpected grant_type %q", r.FormValue("grant_type"))
+	}
+
+	// Verify the RS256 signature and the claims of the assertion
+	parts := strings.Split(r.FormValue("assertion"), ".")
+	if len(parts) != 3 {
+		http.Error(w, "malformed assertion", http.StatusBadRequest)
+		return
+	}
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(f.publicKey, crypto.SHA256, digest[:], signature); err != nil {
+		http.Error(w, "bad signature", http.StatusUnauthorized)
+		return
+	}
+
+	claimsJSON, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims map[string]any
+	json.Unmarshal(claimsJSON, &claims)
+	if claims["iss"] != "poller@example.iam.gserviceaccount.com" {
+		f.t.Errorf("Unexpected iss claim %v", claims["iss"])
+	}
+	if claims["scope"] != googlePlayScope {
+		f.t.Errorf("Unexpected scope claim %v", claims["scope"])
+	}
+	if claims["aud"] != f.URL+"/token" {
+		f.t.Errorf("Expected aud claim %q, got %v", f.URL+"/token", claims["aud"])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"access_token":"test-token","expires_in":3600,"token_type":"Bearer"}`))
+}
+
+func (f *fakeGooglePlay) handleReviews(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.pageRequests++
+	f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer test-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	start := 0
+	if token := r.URL.Query().Get("token"); token != "" {
+		fmt.Sscanf(token, "page-%d", &start)
+	}
+	end := min(start+f.pageSize, len(f.reviewIDs))
+
+	var reviews []string
+	for i, id := range f.reviewIDs[start:end] {
+		reviews = append(reviews, fmt.Sprintf(
+			`{"reviewId":%q,"authorName":"User %d","comments":[{"userComment":{"text":"\tNice app","starRating":4,"appVersionName":"2.1.0","lastModified":{"seconds":"1700000000","nanos":0}}},{"developerComment":{"text":"Thanks!"}}]}`,
+			id, start+i))
+	}
+	next := ""
+	if end < len(f.reviewIDs) {
+		next = fmt.Sprintf(`,"tokenPagination":{"nextPageToken":"page-%d"}`, end)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"reviews":[%s]%s}`, strings.Join(reviews, ","), next)
+}
+
+// writeServiceAccountKey generates an RSA key and writes it as a service account JSON key
+func writeServiceAccountKey(t *testing.T, tokenURI string) (string, *rsa.PublicKey) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	key, _ := json.Marshal(serviceAccountKey{
+		Type:         "service_account",
+		ClientEmail:  "poller@example.iam.gserviceaccount.com",
+		PrivateKeyID: "key1",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		TokenURI:     tokenURI,
+	})
+	path := filepath.Join(t.TempDir(), "service-account.json")
+	if err := os.WriteFile(path, key, 0600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	return path, &privateKey.PublicKey
+}
+
+func newGooglePlayTestPoller(t *testing.T, reviewIDs []string, pageSize int) (*Poller, *fakeGooglePlay, *testutil.MockStorage) {
+	t.Helper()
+
+	// The key's token_uri is only known once the server runs, so start it first
+	var publicKey rsa.PublicKey
+	server := newFakeGooglePlay(t, &publicKey, reviewIDs, pageSize)
+	keyFile, key := writeServiceAccountKey(t, server.URL+"/token")
+	publicKey = *key
+
+	storage := testutil.NewMockStorage()
+	apps := []models.App{{ID: "com.example.app", Platform: models.PlatformAndroid}}
+	poller := NewPoller(storage, log.New(io.Discard, "", 0), apps, time.Second,
+		WithGooglePlay(GooglePlayConfig{ServiceAccountFile: keyFile, BaseURL: server.URL}))
+
+	if _, ok := poller.sources[models.PlatformAndroid]; !ok {
+		t.Fatal("Expected Google Play source to be configured")
+	}
+	return poller, server, storage
+}
+
+func TestGooglePlaySource_FetchReviews(t *testing.T) {
+	poller, server, storage := newGooglePlayTestPoller(t, []string{"g1", "g2", "g3", "g4", "g5"}, 2)
+
+	poller.pollAllAppsConcurrently()
+
+	reviews, _ := storage.GetAllReviews()
+	if len(reviews) != 5 {
+		t.Fatalf("Expected 5 reviews across 3 pages, got %d", len(reviews))
+	}
+
+	review := reviews[0]
+	if review.Platform != models.PlatformAndroid {
+		t.Errorf("Expected Platform %q, got %q", models.PlatformAndroid, review.Platform)
+	}
+	if review.AppID != "com.example.app" || review.Country != "" {
+		t.Errorf("Expected global review of com.example.app, got app %q country %q", review.AppID, review.Country)
+	}
+	if review.Content != "Nice app" || review.Rating != 4 {
+		t.Errorf("Expected user comment to be mapped, got content %q rating %d", review.Content, review.Rating)
+	}
+	if review.Version != "2.1.0" {
+		t.Errorf("Expected Version from appVersionName, got %q", review.Version)
+	}
+	if !review.SubmittedAt.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Expected SubmittedAt from lastModified, got %v", review.SubmittedAt)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.pageRequests != 3 {
+		t.Errorf("Expected 3 page requests, got %d", server.pageRequests)
+	}
+	if server.tokenRequests != 1 {
+		t.Errorf("Expected access token to be reused across pages, got %d token requests", server.tokenRequests)
+	}
+}
+
+func TestGooglePlaySource_StopsAtStoredReviews(t *testing.T) {
+	poller, server, storage := newGooglePlayTestPoller(t, []string{"g1", "g2", "g3", "g4"}, 2)
+	storage.SaveReviews([]models.Review{{ID: "g2", AppID: "com.example.app"}})
+
+	poller.pollAllAppsConcurrently()
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.pageRequests != 1 {
+		t.Errorf("Expected polling to stop at the page with a stored review, got %d page requests", server.pageRequests)
+	}
+}
+
+func TestServiceAccountTokens_RenewedBeforeExpiry(t *testing.T) {
+	poller, server, _ := newGooglePlayTestPoller(t, nil, 10)
+	tokens := poller.sources[models.PlatformAndroid].(*GooglePlaySource).tokens
+
+	clock := &fakeClock{now: time.Now()}
+	tokens.now = clock.Now
+
+	for i := 0; i < 2; i++ {
+		if _, err := tokens.accessToken(poller.newSourceClient("test")); err != nil {
+			t.Fatalf("accessToken failed: %v", err)
+		}
+	}
+
+	// Within a minute of the one hour expiry the token is renewed
+	clock.now = clock.now.Add(59*time.Minute + time.Second)
+	if _, err := tokens.accessToken(poller.newSourceClient("test")); err != nil {
+		t.Fatalf("accessToken failed: %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.tokenRequests != 2 {
+		t.Errorf("Expected 2 token requests, got %d", server.tokenRequests)
+	}
+}
+
+func TestGooglePlaySource_RenewsRejectedToken(t *testing.T) {
+	poller, server, storage := newGooglePlayTestPoller(t, []string{"g1"}, 10)
+	tokens := poller.sources[models.PlatformAndroid].(*GooglePlaySource).tokens
+
+	// A cached token that was revoked before it expired
+	tokens.token = "revoked"
+	tokens.expiry = time.Now().Add(time.Hour)
+
+	poller.pollAllAppsConcurrently()
+
+	if storage.GetSavedReviewCount() != 1 {
+		t.Errorf("Expected the poll to succeed with a new token, got %d reviews", storage.GetSavedReviewCount())
+	}
+	if breaker := poller.Status()[0].Breakers[0]; breaker.ConsecutiveFailures != 0 {
+		t.Errorf("Expected the rejected token not to count as a failure, got %+v", breaker)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.tokenRequests != 1 || server.pageRequests != 2 {
+		t.Errorf("Expected one token request and a retried page request, got %d and %d", server.tokenRequests, server.pageRequests)
+	}
+}
+
+func TestNewGooglePlaySource_InvalidKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key.json")
+	os.WriteFile(path, []byte(`{"client_email":"poller@example.com","private_key":"not a key"}`), 0600)
+
+	_, err := NewGooglePlaySource(GooglePlayConfig{ServiceAccountFile: path}, nil)
+	if err == nil {
+		t.Fatal("Expected error for invalid private key, got nil")
+	}
+	if !strings.Contains(err.Error(), "private_key") {
+		t.Errorf("Expected private key error, got: %v", err)
+	}
+}
+
+func TestPoller_AndroidAppWithoutSource(t *testing.T) {
+	var buf testutil.SafeBuffer
+	apps := []models.App{{ID: "com.example.app", Platform: models.PlatformAndroid}}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(&buf, "", 0), apps, time.Second)
+
+	poller.pollAllAppsConcurrently()
+
+	if !strings.Contains(buf.String(), `Error polling app com.example.app: no review source for platform "android"`) {
+		t.Errorf("Expected missing source error, got: %s", buf.String())
+	}
+}
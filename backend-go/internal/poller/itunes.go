@@ -0,0 +1,266 @@
+package poller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/httpclient"
+	"backend/internal/models"
+)
+
+// feedBaseURL is the iTunes host serving the customer reviews RSS feeds
+var feedBaseURL = "https://itunes.apple.com"
+
+// maxFeedPages is the number of pages the iTunes customer reviews feed serves (50 reviews each)
+const maxFeedPages = 10
+
+// ITunesSource reads the public iTunes customer reviews RSS feed of iOS apps
+type ITunesSource struct {
+	logger *log.Logger
+}
+
+func NewITunesSource(logger *log.Logger) *ITunesSource {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &ITunesSource{logger: logger}
+}
+
+func (s *ITunesSource) Platform() string {
+	return models.PlatformIOS
+}
+
+// Sort orders of the iTunes customer reviews feed
+const (
+	SortMostRecent  = "mostRecent"
+	SortMostHelpful = "mostHelpful"
+)
+
+// FetchReviews walks the feed of one storefront newest first, following the
+// rel="next" links until it reaches reviews we already have
+func (s *ITunesSource) FetchReviews(client HTTPClient, req FetchRequest) ([]models.Review, error) {
+	stats := req.Stats
+	if stats == nil {
+		stats = &ParseStats{}
+	}
+	return s.walkFeed(client, req.App.ID, req.Country, SortMostRecent, req.Seen, stats)
+}
+
+// FetchAllReviews walks every page the feed of one storefront serves in the
+// given sort order, regardless of which reviews are already stored
+func (s *ITunesSource) FetchAllReviews(client HTTPClient, appID, country, sortBy string, stats *ParseStats) ([]models.Review, error) {
+	return s.walkFeed(client, appID, country, sortBy, nil, stats)
+}
+
+// walkFeed follows the rel="next" links of a feed from its first page until
+// the last page or a page containing a review seen reports as stored
+func (s *ITunesSource) walkFeed(client HTTPClient, appID, country, sortBy string, seen func(id string) bool, stats *ParseStats) ([]models.Review, error) {
+	url := fmt.Sprintf(
+		"%s/%s/rss/customerreviews/id=%s/sortBy=%s/page=1/json",
+		feedBaseURL, neturl.PathEscape(country), neturl.PathEscape(appID), sortBy,
+	)
+
+	var reviews []models.Review
+	for page := 1; page <= maxFeedPages && url != ""; page++ {
+		pageReviews, nextURL, err := s.fetchPage(client, url, appID, country, stats)
+		if errors.Is(err, ErrNotModified) {
+			if page == 1 {
+				return nil, err
+			}
+			break // Nothing new further down either
+		}
+		if err != nil {
+			return reviews, fmt.Errorf("failed to fetch page %d: %w", page, err)
+		}
+
+		reviews = append(reviews, pageReviews...)
+
+		if len(pageReviews) == 0 || containsSeen(pageReviews, seen) {
+			break
+		}
+		if nextURL == url {
+			break // Last page links to itself
+		}
+		url = nextURL
+	}
+
+	return reviews, nil
+}
+
+// fetchPage fetches a single feed page and returns its reviews together
+// with the URL of the next page (empty when there is none). Entries that are
+// not reviews are skipped and counted in stats.
+func (s *ITunesSource) fetchPage(client HTTPClient, url, appID, country string, stats *ParseStats) ([]models.Review, string, error) {
+	// Send HTTP request, retrying transient failures
+	resp, err := client.Get(url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	// Parse RSS feed, an empty body is an empty feed
+	var feed RSSFeed
+	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil && err != io.EOF {
+		return nil, "", feedDecodeError(resp, "RSS feed", err)
+	}
+
+	return s.parsePage(feed, appID, country, time.Now(), stats), nextPageURL(feed.Feed.Link, resp.Request.URL), nil
+}
+
+// parsePage converts the entries of a feed page fetched at fetchedAt to
+// reviews. Entries that are not reviews are skipped and counted in stats.
+func (s *ITunesSource) parsePage(feed RSSFeed, appID, country string, fetchedAt time.Time, stats *ParseStats) []models.Review {
+	reviews := make([]models.Review, 0, len(feed.Feed.Entry))
+	for _, entry := range feed.Feed.Entry {
+		review, err := parseITunesEntry(entry, appID, country, fetchedAt)
+		if err != nil {
+			reason := skipMalformedEntry
+			var skipped *skipError
+			if errors.As(err, &skipped) {
+				reason = skipped.reason
+			}
+			stats.skip(reason)
+			if reason != skipMetadataEntry {
+				stats.observeFields(entry.fields)
+				s.logger.Printf("Warning: failed to parse review entry: %v", err)
+			}
+			continue
+		}
+		stats.observeFields(entry.fields)
+		stats.parsed()
+		reviews = append(reviews, review)
+	}
+	return reviews
+}
+
+// parseRecording parses a recorded page of an RSS feed
+func (s *ITunesSource) parseRecording(recording httpclient.Recording, stats *ParseStats) ([]models.Review, bool, error) {
+	appID, country, ok := iTunesFeedOf(recording.URL)
+	if !ok {
+		return nil, false, nil
+	}
+
+	var feed RSSFeed
+	if err := json.NewDecoder(strings.NewReader(recording.Body)).Decode(&feed); err != nil && err != io.EOF {
+		return nil, true, fmt.Errorf("failed to decode RSS feed: %w", &decodeError{err})
+	}
+	return s.parsePage(feed, appID, country, recording.RecordedAt, stats), true, nil
+}
+
+// iTunesFeedOf returns the app and storefront of a feed page URL, such as
+// https://itunes.apple.com/us/rss/customerreviews/id=123/sortBy=mostRecent/page=1/json
+func iTunesFeedOf(rawURL string) (appID, country string, ok bool) {
+	url, err := neturl.Parse(rawURL)
+	if err != nil {
+		return "", "", false
+	}
+
+	segments := strings.Split(url.Path, "/")
+	for i := 1; i+1 < len(segments); i++ {
+		if segments[i] != "rss" || !strings.EqualFold(segments[i+1], "customerreviews") {
+			continue
+		}
+		for _, segment := range segments[i+2:] {
+			if id, found := strings.CutPrefix(segment, "id="); found {
+				return id, segments[i-1], id != "" && segments[i-1] != ""
+			}
+		}
+	}
+	return "", "", false
+}
+
+// nextPageURL returns the JSON URL of the feed's rel="next" link, resolved
+// against the current page URL. iTunes advertises the next page in its XML
+// form, so the format suffix is switched back to JSON.
+func nextPageURL(links []RSSLink, current *neturl.URL) string {
+	for _, link := range links {
+		if link.Attributes.Rel != "next" || link.Attributes.Href == "" {
+			continue
+		}
+
+		next, err := current.Parse(link.Attributes.Href)
+		if err != nil {
+			return ""
+		}
+		if strings.HasSuffix(next.Path, "/xml") {
+			next.Path = strings.TrimSuffix(next.Path, "/xml") + "/json"
+			next.RawPath = ""
+		}
+		return next.String()
+	}
+	return ""
+}
+
+// parseITunesEntry converts a feed entry to a review. Entries that are not
+// reviews are reported with a *skipError.
+func parseITunesEntry(entry RSSEntry, appID, country string, fetchedAt time.Time) (models.Review, error) {
+	if entry.decodeErr != nil {
+		return models.Review{}, &skipError{reason: skipMalformedEntry, err: entry.decodeErr}
+	}
+
+	// The app itself is described by an entry without a rating
+	if entry.Rating.Label == "" && entry.AppName.Label != "" {
+		return models.Review{}, &skipError{reason: skipMetadataEntry}
+	}
+
+	// Parse rating
+	rating, err := strconv.Atoi(entry.Rating.Label)
+	if err != nil {
+		return models.Review{}, &skipError{reason: skipInvalidRating, err: err}
+	}
+
+	// Parse submission timestamp
+	submittedAt, err := time.Parse(time.RFC3339, entry.Updated.Label)
+	if err != nil {
+		return models.Review{}, &skipError{reason: skipInvalidTimestamp, err: err}
+	}
+
+	// Generate a unique ID from the entry ID
+	// The entry.ID.Label looks like: "https://itunes.apple.com/us/review?id=12345&type=..."
+	// We'll use this as the unique identifier
+	reviewID := entry.ID.Label
+	if reviewID == "" {
+		return models.Review{}, &skipError{reason: skipMissingID}
+	}
+
+	// Votes are informational, a missing or malformed count is treated as zero
+	voteSum, _ := strconv.Atoi(entry.VoteSum.Label)
+	voteCount, _ := strconv.Atoi(entry.VoteCount.Label)
+
+	return models.Review{
+		ID:          reviewID,
+		AppID:       appID,
+		Platform:    models.PlatformIOS,
+		Country:     country,
+		Author:      entry.Author.Name.Label,
+		Title:       entry.Title.Label,
+		Content:     entry.Content.Label,
+		Rating:      rating,
+		Version:     entry.Version.Label,
+		VoteSum:     voteSum,
+		VoteCount:   voteCount,
+		Link:        entry.Link.Attributes.Href,
+		SubmittedAt: submittedAt,
+		FetchedAt:   fetchedAt,
+	}, nil
+}
+
+// containsSeen reports whether any of the reviews is already stored
+func containsSeen(reviews []models.Review, seen func(id string) bool) bool {
+	if seen == nil {
+		return false
+	}
+	for _, review := range reviews {
+		if seen(review.ID) {
+			return true
+		}
+	}
+	return false
+}
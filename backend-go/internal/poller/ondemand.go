@@ -0,0 +1,136 @@
+package poller
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"backend/internal/models"
+)
+
+var (
+	// ErrUnknownApp is returned for on-demand polls of an app that is not configured
+	ErrUnknownApp = errors.New("unknown app")
+	// ErrNotRunning is returned for on-demand polls while the poller is stopped
+	ErrNotRunning = errors.New("poller is not running")
+	// ErrCancelled is returned for requests that were cancelled because the poller is stopping
+	ErrCancelled = errors.New("poll cancelled")
+)
+
+// PollResult is the outcome of an on-demand poll of an app
+type PollResult struct {
+	AppID     string `json:"app_id"`
+	Fetched   int    `json:"fetched"`
+	New       int    `json:"new"`                 // Reviews that were not stored before
+	Skipped   bool   `json:"skipped,omitempty"`   // The circuit breakers of all the app's storefronts are open
+	Cancelled bool   `json:"cancelled,omitempty"` // The poller stopped before the poll finished
+	Error     string `json:"error,omitempty"`     // Set when no storefront could be fetched
+}
+
+// inflightPoll is a poll of an app that later requests for the same app wait for
+type inflightPoll struct {
+	done   chan struct{}
+	result pollResult
+}
+
+// inflightPolls tracks the running poll of each app
+type inflightPolls struct {
+	mu    sync.Mutex
+	polls map[string]*inflightPoll
+}
+
+// PollApp polls an app right away, outside its schedule. If the app is
+// already being polled the running poll is awaited instead of starting
+// another one.
+func (p *Poller) PollApp(appID string) (PollResult, error) {
+	app, ok := p.appByID(appID)
+	if !ok {
+		return PollResult{}, fmt.Errorf("%w: %s", ErrUnknownApp, appID)
+	}
+	if !p.trackRequest() {
+		return PollResult{}, ErrNotRunning
+	}
+	defer p.wg.Done()
+
+	p.logger.Printf("On-demand poll of app %s requested", appID)
+	return p.pollMerged(app).summary(app.ID), nil
+}
+
+// PollAll polls every app right away, outside their schedule, merging with
+// polls that are already running
+func (p *Poller) PollAll() ([]PollResult, error) {
+	if !p.trackRequest() {
+		return nil, ErrNotRunning
+	}
+	defer p.wg.Done()
+
+	p.logger.Println("On-demand poll of all apps requested")
+	return p.pollAllAppsConcurrently(), nil
+}
+
+// trackRequest registers an on-demand poll with the running poller, so Stop
+// waits for it. It reports false when the poller is not running.
+func (p *Poller) trackRequest() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.started {
+		return false
+	}
+	p.wg.Add(1)
+	return true
+}
+
+// appByID returns the configured app with the given ID
+func (p *Poller) appByID(appID string) (models.App, bool) {
+	for _, app := range p.Apps() {
+		if app.ID == appID {
+			return app, true
+		}
+	}
+	return models.App{}, false
+}
+
+// pollMerged polls an app, or waits for the poll of the app that is already
+// running and shares its result
+func (p *Poller) pollMerged(app models.App) pollResult {
+	p.inflight.mu.Lock()
+	if poll, ok := p.inflight.polls[app.ID]; ok {
+		p.inflight.mu.Unlock()
+		<-poll.done
+		return poll.result
+	}
+	poll := &inflightPoll{done: make(chan struct{})}
+	p.inflight.polls[app.ID] = poll
+	p.inflight.mu.Unlock()
+
+	defer func() {
+		p.inflight.mu.Lock()
+		delete(p.inflight.polls, app.ID)
+		p.inflight.mu.Unlock()
+		close(poll.done)
+	}()
+
+	start := time.Now()
+	poll.result = p.pollApp(app)
+	if !poll.result.Skipped && !poll.result.Cancelled {
+		p.records.record(app.ID, start, poll.result)
+	}
+	return poll.result
+}
+
+// summary converts the result of a poll of appID for API consumers
+func (r pollResult) summary(appID string) PollResult {
+	result := PollResult{
+		AppID:     appID,
+		Fetched:   r.Fetched,
+		New:       r.New,
+		Skipped:   r.Skipped,
+		Cancelled: r.Cancelled,
+	}
+	if !r.Succeeded && r.Err != nil {
+		result.Error = r.Err.Error()
+	}
+	return result
+}
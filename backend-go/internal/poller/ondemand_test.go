@@ -0,0 +1,227 @@
+package poller
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+func TestPoller_PollApp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(feedPageJSON([]string{"r1", "r2", "r3"}, "")))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	storage := testutil.NewMockStorage()
+	storage.SaveReviews([]models.Review{{ID: "r1", AppID: "123", Country: "us"}})
+	apps := []models.App{{ID: "123", Interval: models.Duration(time.Hour)}}
+	// The scheduled first poll is delayed by up to 30s, well after the test
+	poller := NewPoller(storage, log.New(io.Discard, "", 0), apps, time.Hour, WithJitter(1))
+	poller.Start()
+	defer poller.Stop()
+
+	result, err := poller.PollApp("123")
+	if err != nil {
+		t.Fatalf("PollApp failed: %v", err)
+	}
+	if result.AppID != "123" || result.Fetched != 3 || result.New != 2 || result.Error != "" {
+		t.Errorf("Expected 3 fetched and 2 new reviews for app 123, got %+v", result)
+	}
+	if storage.GetSavedReviewCount() != 3 {
+		t.Errorf("Expected 3 stored reviews, got %d", storage.GetSavedReviewCount())
+	}
+}
+
+func TestPoller_PollAppErrors(t *testing.T) {
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), []models.App{{ID: "123"}}, time.Hour)
+
+	if _, err := poller.PollApp("123"); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning before Start, got %v", err)
+	}
+	if _, err := poller.PollAll(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning from PollAll before Start, got %v", err)
+	}
+	if _, err := poller.PollApp("456"); !errors.Is(err, ErrUnknownApp) {
+		t.Errorf("Expected ErrUnknownApp, got %v", err)
+	}
+}
+
+func TestPoller_PollAppMergesWithRunningPoll(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+		w.Write([]byte(feedPageJSON([]string{"r1", "r2"}, "")))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	apps := []models.App{{ID: "123", Interval: models.Duration(time.Hour)}}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), apps, time.Hour, WithJitter(0))
+	poller.Start()
+	defer poller.Stop()
+
+	// Without jitter the scheduled poll starts right away and blocks in the server
+	deadline := time.Now().Add(time.Second)
+	for requests.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	var wg sync.WaitGroup
+	results := make([]PollResult, 2)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = poller.PollApp("123")
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if requests.Load() != 1 {
+		t.Errorf("Expected on-demand polls to share the running poll, got %d requests", requests.Load())
+	}
+	for _, result := range results {
+		if result.New != 2 {
+			t.Errorf("Expected every caller to see the 2 new reviews, got %+v", result)
+		}
+	}
+}
+
+func TestPoller_PollAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/us/rss/customerreviews/id=broken/sortBy=mostRecent/page=1/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(feedPageJSON([]string{"r-" + r.URL.Path}, "")))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	apps := []models.App{
+		{ID: "123", Interval: models.Duration(time.Hour)},
+		{ID: "broken", Interval: models.Duration(time.Hour)},
+	}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), apps, time.Hour, WithJitter(1))
+	poller.Start()
+	defer poller.Stop()
+
+	results, err := poller.PollAll()
+	if err != nil {
+		t.Fatalf("PollAll failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected a result per app, got %+v", results)
+	}
+	if results[0].AppID != "123" || results[0].New != 1 || results[0].Error != "" {
+		t.Errorf("Expected one new review for app 123, got %+v", results[0])
+	}
+	if results[1].AppID != "broken" || results[1].Error == "" {
+		t.Errorf("Expected an error for the broken app, got %+v", results[1])
+	}
+}
+
+func TestPoller_StatusRecordsPolls(t *testing.T) {
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(feedPageJSON([]string{"r1", "r2"}, "")))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	apps := []models.App{{ID: "123"}}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), apps, time.Hour)
+
+	before := time.Now()
+	poller.pollAllAppsConcurrently()
+
+	status := poller.Status()[0]
+	if status.LastAttempt == nil || status.LastAttempt.Before(before) {
+		t.Fatalf("Expected last attempt to be recorded, got %+v", status)
+	}
+	if status.LastSuccess == nil || status.ReviewsFetched != 2 || status.ReviewsNew != 2 || status.LastError != "" {
+		t.Errorf("Expected a successful poll with 2 new reviews, got %+v", status)
+	}
+	lastSuccess := *status.LastSuccess
+
+	failing.Store(true)
+	poller.pollAllAppsConcurrently()
+	poller.pollAllAppsConcurrently()
+
+	status = poller.Status()[0]
+	if status.ConsecutiveFailures != 2 {
+		t.Errorf("Expected 2 consecutive failures, got %d", status.ConsecutiveFailures)
+	}
+	if status.LastError == "" || status.LastErrorAt == nil {
+		t.Errorf("Expected the last error to be recorded, got %+v", status)
+	}
+	if !status.LastSuccess.Equal(lastSuccess) {
+		t.Errorf("Expected last success to stay at %v, got %v", lastSuccess, status.LastSuccess)
+	}
+	if status.ReviewsFetched != 0 || status.ReviewsNew != 0 {
+		t.Errorf("Expected counts of the failed poll, got %d fetched and %d new", status.ReviewsFetched, status.ReviewsNew)
+	}
+
+	// A successful poll clears the error again
+	failing.Store(false)
+	poller.pollAllAppsConcurrently()
+
+	status = poller.Status()[0]
+	if status.ConsecutiveFailures != 0 || status.LastError != "" || status.LastErrorAt != nil {
+		t.Errorf("Expected the error to be cleared after a successful poll, got %+v", status)
+	}
+}
+
+func TestPoller_StatusBeforeFirstPoll(t *testing.T) {
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), []models.App{{ID: "123"}}, time.Hour)
+
+	status := poller.Status()[0]
+	if status.LastAttempt != nil || status.LastSuccess != nil || status.LastErrorAt != nil {
+		t.Errorf("Expected no poll times before the first poll, got %+v", status)
+	}
+}
+
+func TestPoller_StatusDoesNotCreateState(t *testing.T) {
+	apps := []models.App{{ID: "123", Countries: []string{"us", "de"}}}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), apps, time.Hour,
+		WithAdaptiveInterval(AdaptiveConfig{MaxFactor: 4, TargetNewReviews: 1}))
+
+	status := poller.Status()[0]
+	if len(status.Breakers) != 2 || status.Breakers[0].State != BreakerClosed || status.Interval != models.Duration(time.Hour) {
+		t.Errorf("Expected closed breakers and the base interval before the first poll, got %+v", status)
+	}
+	if len(poller.breakers) != 0 || len(poller.intervals) != 0 {
+		t.Errorf("Expected a status request not to create breakers or adaptive intervals, got %d and %d",
+			len(poller.breakers), len(poller.intervals))
+	}
+}
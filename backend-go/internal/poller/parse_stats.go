@@ -0,0 +1,137 @@
+package poller
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Reasons feed entries are skipped, counted in ParseStats
+const (
+	skipMetadataEntry    = "app metadata entry"
+	skipMalformedEntry   = "malformed entry"
+	skipMissingID        = "missing id"
+	skipInvalidRating    = "invalid rating"
+	skipInvalidTimestamp = "invalid timestamp"
+	skipNoUserComment    = "no user comment"
+)
+
+// ParseStats counts how the entries of the fetched feed pages were turned into reviews
+type ParseStats struct {
+	Seen        int            `json:"seen"`
+	Parsed      int            `json:"parsed"`
+	Skipped     int            `json:"skipped"`
+	SkipReasons map[string]int `json:"skip_reasons,omitempty"` // Skipped entries per reason
+
+	// Fields of the review entries (all but app metadata), for sources that report them
+	reviewEntries int
+	fields        map[string]int // Review entries containing each field
+}
+
+func (s *ParseStats) parsed() {
+	s.Seen++
+	s.Parsed++
+}
+
+func (s *ParseStats) skip(reason string) {
+	s.Seen++
+	s.Skipped++
+	if s.SkipReasons == nil {
+		s.SkipReasons = make(map[string]int)
+	}
+	s.SkipReasons[reason]++
+}
+
+// observeFields counts the fields of a review entry
+func (s *ParseStats) observeFields(fields []string) {
+	s.reviewEntries++
+	if s.fields == nil {
+		s.fields = make(map[string]int)
+	}
+	for _, field := range fields {
+		s.fields[field]++
+	}
+}
+
+// add merges the counts of other into s
+func (s *ParseStats) add(other ParseStats) {
+	s.Seen += other.Seen
+	s.Parsed += other.Parsed
+	s.Skipped += other.Skipped
+	for reason, count := range other.SkipReasons {
+		if s.SkipReasons == nil {
+			s.SkipReasons = make(map[string]int)
+		}
+		s.SkipReasons[reason] += count
+	}
+	s.reviewEntries += other.reviewEntries
+	for field, count := range other.fields {
+		if s.fields == nil {
+			s.fields = make(map[string]int)
+		}
+		s.fields[field] += count
+	}
+}
+
+// unexpectedSkips returns the number of skipped entries that were not app
+// metadata, which some storefronts put first in every feed
+func (s ParseStats) unexpectedSkips() int {
+	return s.Skipped - s.SkipReasons[skipMetadataEntry]
+}
+
+// String summarizes the stats for logs, e.g. "48 of 50 parsed, skipped: 1 app metadata entry, 1 invalid rating"
+func (s ParseStats) String() string {
+	summary := fmt.Sprintf("%d of %d parsed", s.Parsed, s.Seen)
+	if s.Skipped == 0 {
+		return summary
+	}
+
+	reasons := make([]string, 0, len(s.SkipReasons))
+	for _, reason := range slices.Sorted(maps.Keys(s.SkipReasons)) {
+		reasons = append(reasons, fmt.Sprintf("%d %s", s.SkipReasons[reason], reason))
+	}
+	return summary + ", skipped: " + strings.Join(reasons, ", ")
+}
+
+// skipError explains why a feed entry was not turned into a review
+type skipError struct {
+	reason string // Counted in ParseStats
+	err    error  // Details, may be nil
+}
+
+func (e *skipError) Error() string {
+	if e.err == nil {
+		return e.reason
+	}
+	return fmt.Sprintf("%s: %v", e.reason, e.err)
+}
+
+func (e *skipError) Unwrap() error {
+	return e.err
+}
+
+// decodeError is returned by sources when a whole feed page could not be
+// decoded, so none of its entries were seen
+type decodeError struct {
+	err error
+}
+
+func (e *decodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *decodeError) Unwrap() error {
+	return e.err
+}
+
+// feedDecodeError wraps err, returned while decoding the body of resp. A body
+// cut off because the poller is stopping is ErrCancelled rather than a feed
+// that could not be decoded, so it neither trips breakers nor flags drift.
+func feedDecodeError(resp *http.Response, what string, err error) error {
+	if resp.Request != nil && resp.Request.Context().Err() != nil {
+		return fmt.Errorf("%w: %v", ErrCancelled, err)
+	}
+	return fmt.Errorf("failed to decode %s: %w", what, &decodeError{err})
+}
@@ -0,0 +1,139 @@
+package poller
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+// metadataEntryJSON is the app entry some storefronts send before the reviews
+const metadataEntryJSON = `{"im:name":{"label":"Example App"},"im:artist":{"label":"Example Inc."},` +
+	`"id":{"label":"https://apps.apple.com/us/app/example/id123"}}`
+
+func TestRSSFeed_SingleEntryObject(t *testing.T) {
+	var feed RSSFeed
+	err := json.Unmarshal([]byte(`{"feed":{
+		"entry":{"im:rating":{"label":"5"},"updated":{"label":"2023-01-15T10:30:00Z"},"id":{"label":"r1"}},
+		"link":{"attributes":{"rel":"next","href":"https://example.com/page=2/json"}}
+	}}`), &feed)
+	if err != nil {
+		t.Fatalf("Failed to decode feed with single entry: %v", err)
+	}
+
+	if len(feed.Feed.Entry) != 1 || feed.Feed.Entry[0].ID.Label != "r1" {
+		t.Errorf("Expected the single entry r1, got %+v", feed.Feed.Entry)
+	}
+	if len(feed.Feed.Link) != 1 || feed.Feed.Link[0].Attributes.Rel != "next" {
+		t.Errorf("Expected the single next link, got %+v", feed.Feed.Link)
+	}
+
+	if err := json.Unmarshal([]byte(`{"feed":{"entry":"oops"}}`), &feed); err == nil {
+		t.Error("Expected error for an entry that is neither object nor array, got nil")
+	}
+}
+
+func TestPoller_fetchPageParseStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"feed":{"entry":[` + strings.Join([]string{
+			metadataEntryJSON,
+			`{"im:rating":{"label":"5"},"updated":{"label":"2023-01-15T10:30:00Z"},"id":{"label":"r1"}}`,
+			`{"author":{"name":{"label":"No Rating"}},"updated":{"label":"2023-01-15T10:30:00Z"},"id":{"label":"r2"}}`,
+			`{"im:rating":{"label":4},"id":{"label":"r3"}}`,
+			`{"im:rating":{"label":"3"},"updated":{"label":"2023-01-15T10:30:00Z"}}`,
+			`{"im:rating":{"label":"2"},"updated":{"label":"yesterday"},"id":{"label":"r5"}}`,
+		}, ",") + `]}}`))
+	}))
+	defer server.Close()
+
+	var logs testutil.SafeBuffer
+	poller := NewPoller(testutil.NewMockStorage(), log.New(&logs, "", 0), nil, time.Hour)
+	source := poller.sources[models.PlatformIOS].(*ITunesSource)
+
+	var stats ParseStats
+	reviews, _, err := source.fetchPage(poller.newSourceClient("test"), server.URL, "123", "us", &stats)
+	if err != nil {
+		t.Fatalf("fetchPage failed: %v", err)
+	}
+
+	if len(reviews) != 1 || reviews[0].ID != "r1" {
+		t.Fatalf("Expected only review r1, got %+v", reviews)
+	}
+	expected := map[string]int{
+		skipMetadataEntry:    1,
+		skipInvalidRating:    1,
+		skipMalformedEntry:   1,
+		skipMissingID:        1,
+		skipInvalidTimestamp: 1,
+	}
+	if stats.Seen != 6 || stats.Parsed != 1 || stats.Skipped != 5 {
+		t.Errorf("Expected 6 seen, 1 parsed and 5 skipped, got %+v", stats)
+	}
+	for reason, count := range expected {
+		if stats.SkipReasons[reason] != count {
+			t.Errorf("Expected %d entries skipped for %q, got %+v", count, reason, stats.SkipReasons)
+		}
+	}
+	if strings.Contains(logs.String(), skipMetadataEntry) {
+		t.Errorf("Expected the metadata entry to be skipped quietly, got: %s", logs.String())
+	}
+}
+
+func TestPoller_fetchPageEmptyFeed(t *testing.T) {
+	bodies := []string{``, `{}`, `{"feed":{}}`, `{"feed":null}`, `{"feed":{"entry":null,"link":[]}}`}
+
+	for _, body := range bodies {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}))
+
+		poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), nil, time.Hour)
+		reviews, next, err := fetchFeedPage(poller, server.URL, "123", "us")
+		if err != nil || len(reviews) != 0 || next != "" {
+			t.Errorf("Body %q: expected an empty page, got %d reviews, next %q, error %v", body, len(reviews), next, err)
+		}
+		server.Close()
+	}
+}
+
+func TestPoller_StatusParseStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := feedPageJSON([]string{"r1", "r2"}, "")
+		w.Write([]byte(strings.Replace(page, `"entry":[`, `"entry":[`+metadataEntryJSON+",", 1)))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	apps := []models.App{{ID: "123", Countries: []string{"us", "de"}}}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), apps, time.Hour)
+	poller.pollAllAppsConcurrently()
+
+	parse := poller.Status()[0].Parse
+	if parse.Seen != 6 || parse.Parsed != 4 || parse.Skipped != 2 || parse.SkipReasons[skipMetadataEntry] != 2 {
+		t.Errorf("Expected the stats of both storefronts, got %+v", parse)
+	}
+}
+
+func TestParseStats_String(t *testing.T) {
+	stats := ParseStats{}
+	stats.parsed()
+	stats.skip(skipInvalidRating)
+	stats.skip(skipMetadataEntry)
+
+	if s := stats.String(); s != "1 of 3 parsed, skipped: 1 app metadata entry, 1 invalid rating" {
+		t.Errorf("Unexpected summary %q", s)
+	}
+	if stats.unexpectedSkips() != 1 {
+		t.Errorf("Expected 1 unexpected skip, got %d", stats.unexpectedSkips())
+	}
+}
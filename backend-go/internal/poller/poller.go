@@ -1,14 +1,16 @@
 package poller
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
-	"strconv"
 	"sync"
 	"time"
 
+	"backend/internal/httpclient"
 	"backend/internal/models"
 	"backend/internal/storage"
 )
@@ -17,28 +19,156 @@ type Poller struct {
 	storage      storage.Storage
 	logger       *log.Logger
 	client       *http.Client
-	appIDs       []string
-	pollInterval time.Duration
-	stopChan     chan struct{}
+	userAgent    string
+	apps         []models.App
+	pollInterval time.Duration // Default interval for apps without their own
+	appsMu       sync.RWMutex  // Guards apps and pollInterval
+	appsChanged  chan struct{} // Tells the scheduler to pick up SetApps and SetInterval
+	jitter       float64
+	adaptiveCfg  AdaptiveConfig
+	intervals    map[string]*adaptiveInterval // Adaptive interval per app
+	intervalsMu  sync.Mutex
+	retry        RetryConfig
+	breakerCfg   BreakerConfig
+	breakers     map[string]map[string]*circuitBreaker // Per app and storefront
+	breakersMu   sync.Mutex
+	limiter      *tokenBucket
+	validators   *validatorStore
+	sources      map[string]ReviewSource // Review source per platform
+	workers      chan struct{}           // Semaphore bounding concurrently fetched storefronts
+	inflight     inflightPolls
+	records      pollRecords     // Outcome of the latest poll per app
+	schemas      schemaMonitor   // Schema drift per feed
+	stopChan     chan struct{}   // Closed on Stop, no new polls start
+	ctx          context.Context // Cancelled on Stop, aborts running polls
+	cancel       context.CancelFunc
 	wg           sync.WaitGroup
 	mu           sync.Mutex
 	started      bool
 }
 
-func NewPoller(storage storage.Storage, logger *log.Logger, appIDs []string, interval time.Duration) *Poller {
+// Option customizes a Poller created by NewPoller
+type Option func(*Poller)
+
+// WithHTTPClient sends the store requests through a copy of client, e.g. one
+// built by httpclient.New with a proxy or custom CA
+func WithHTTPClient(client *http.Client) Option {
+	return func(p *Poller) {
+		copied := *client
+		p.client = &copied
+	}
+}
+
+// WithRoundTripper replaces the transport of the poller's HTTP client,
+// e.g. with a fake in tests
+func WithRoundTripper(transport http.RoundTripper) Option {
+	return func(p *Poller) {
+		p.client.Transport = transport
+	}
+}
+
+// WithUserAgent sets the User-Agent header of store requests
+func WithUserAgent(userAgent string) Option {
+	return func(p *Poller) {
+		p.userAgent = userAgent
+	}
+}
+
+// WithRetry sets how failed feed requests are retried
+func WithRetry(config RetryConfig) Option {
+	return func(p *Poller) {
+		p.retry = config
+	}
+}
+
+// WithCircuitBreaker sets when an app storefront that keeps failing is paused
+func WithCircuitBreaker(config BreakerConfig) Option {
+	return func(p *Poller) {
+		p.breakerCfg = config
+	}
+}
+
+// WithMaxConcurrency limits how many app storefronts are fetched at the same time
+func WithMaxConcurrency(n int) Option {
+	return func(p *Poller) {
+		p.workers = make(chan struct{}, max(n, 1))
+	}
+}
+
+// WithRateLimit sets the request rate shared by all feed fetches
+func WithRateLimit(config RateLimitConfig) Option {
+	return func(p *Poller) {
+		p.limiter = newTokenBucket(config)
+	}
+}
+
+// WithValidatorStore persists the ETag / Last-Modified validators of each
+// feed URL at path, so conditional requests keep working across restarts
+func WithValidatorStore(path string) Option {
+	return func(p *Poller) {
+		store, err := loadValidatorStore(path)
+		if err != nil {
+			p.logger.Printf("Warning: %v, starting without feed validators", err)
+		}
+		p.validators = store
+	}
+}
+
+// WithSource adds a review source, replacing any source for the same platform
+func WithSource(source ReviewSource) Option {
+	return func(p *Poller) {
+		p.sources[source.Platform()] = source
+	}
+}
+
+// WithGooglePlay polls Android apps through the Google Play Developer API
+func WithGooglePlay(config GooglePlayConfig) Option {
+	return func(p *Poller) {
+		source, err := NewGooglePlaySource(config, p.logger)
+		if err != nil {
+			p.logger.Printf("Warning: %v, Android apps will not be polled", err)
+			return
+		}
+		p.sources[source.Platform()] = source
+	}
+}
+
+func NewPoller(storage storage.Storage, logger *log.Logger, apps []models.App, interval time.Duration, opts ...Option) *Poller {
 	if logger == nil {
 		logger = log.Default()
 	}
-	return &Poller{
+	p := &Poller{
 		storage: storage,
 		logger:  logger,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		appIDs:       appIDs,
+		userAgent:    httpclient.DefaultUserAgent,
+		apps:         apps,
 		pollInterval: interval,
+		jitter:       DefaultJitter,
+		adaptiveCfg:  DefaultAdaptiveConfig(),
+		intervals:    make(map[string]*adaptiveInterval),
+		retry:        DefaultRetryConfig(),
+		breakerCfg:   DefaultBreakerConfig(),
+		breakers:     make(map[string]map[string]*circuitBreaker),
+		limiter:      newTokenBucket(DefaultRateLimitConfig()),
+		validators:   newValidatorStore(),
+		workers:      make(chan struct{}, DefaultMaxConcurrency),
+		appsChanged:  make(chan struct{}, 1),
 		stopChan:     make(chan struct{}),
+		inflight:     inflightPolls{polls: make(map[string]*inflightPoll)},
+		records:      pollRecords{apps: make(map[string]*pollRecord)},
+		schemas:      schemaMonitor{feeds: make(map[string]map[string]*feedSchema)},
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	p.sources = map[string]ReviewSource{
+		models.PlatformIOS: NewITunesSource(logger),
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
 func (p *Poller) Start() {
@@ -54,150 +184,392 @@ func (p *Poller) Start() {
 	go p.run()
 }
 
-func (p *Poller) run() {
-	defer p.wg.Done()
+// Apps returns the apps being polled
+func (p *Poller) Apps() []models.App {
+	p.appsMu.RLock()
+	defer p.appsMu.RUnlock()
+	return append([]models.App(nil), p.apps...)
+}
 
-	// Poll immediately on startup (e.g. after restart)
-	p.pollAllAppsConcurrently()
+// SetApps replaces the apps being polled. New apps are scheduled right away,
+// removed apps are no longer polled once a running poll finished, and a
+// changed interval applies from the next poll on.
+func (p *Poller) SetApps(apps []models.App) {
+	p.appsMu.Lock()
+	previous := p.apps
+	p.apps = append([]models.App(nil), apps...)
+	p.appsMu.Unlock()
+
+	// Forget the state of removed apps, so adding one again starts afresh
+	kept := make(map[string]bool, len(apps))
+	for _, app := range apps {
+		kept[app.ID] = true
+	}
+	for _, app := range previous {
+		if !kept[app.ID] {
+			p.forgetApp(app.ID)
+		}
+	}
 
-	ticker := time.NewTicker(p.pollInterval)
-	defer ticker.Stop()
+	p.notifyScheduler()
+}
 
-	for {
-		select {
-		case <-ticker.C:
-			p.pollAllAppsConcurrently()
-		case <-p.stopChan:
-			return
-		}
+// forgetApp drops the breakers, adaptive interval, poll record and schema
+// drift of an app that is no longer tracked
+func (p *Poller) forgetApp(appID string) {
+	p.breakersMu.Lock()
+	delete(p.breakers, appID)
+	p.breakersMu.Unlock()
+
+	p.resetAdaptive(appID)
+	p.records.forget(appID)
+	p.schemas.forget(appID)
+}
+
+// SetInterval changes the default interval of apps without their own
+func (p *Poller) SetInterval(interval time.Duration) {
+	p.appsMu.Lock()
+	p.pollInterval = interval
+	p.appsMu.Unlock()
+
+	p.notifyScheduler()
+}
+
+// notifyScheduler tells the scheduler that apps or intervals changed
+func (p *Poller) notifyScheduler() {
+	select {
+	case p.appsChanged <- struct{}{}:
+	default:
+		// The scheduler has not picked up the previous change yet, it will see this one too
 	}
 }
 
-// TODO: check if rate-limiting is needed
-func (p *Poller) pollAllAppsConcurrently() {
+// pollAllAppsConcurrently polls every app at once on a bounded pool of workers,
+// regardless of their schedule. Feed requests are additionally throttled by the
+// shared rate limiter. Apps that are already being polled are not polled twice.
+func (p *Poller) pollAllAppsConcurrently() []PollResult {
 	p.logger.Println("Polling all apps concurrently...")
 
 	start := time.Now()
 
+	apps := p.Apps()
+	results := make([]PollResult, len(apps))
 	var wg sync.WaitGroup
 
-	for _, appID := range p.appIDs {
+	for i, app := range apps {
 		wg.Add(1)
-
-		// Launch goroutine for each app
-		go func(id string) {
+		go func() {
 			defer wg.Done()
-
-			if err := p.fetchAndStore(id); err != nil {
-				p.logger.Printf("Error polling app %s: %v", id, err)
-			} else {
-				p.logger.Printf("Successfully polled app %s", id)
-			}
-		}(appID)
+			results[i] = p.pollMerged(app).summary(app.ID)
+		}()
 	}
 
 	// Wait for all apps to complete
 	wg.Wait()
 
 	p.logger.Printf("Poll complete in %v", time.Since(start))
+	return results
 }
 
-func (p *Poller) fetchAndStore(appID string) error {
-	p.logger.Printf("Fetching reviews for app %s", appID)
+// pollResult summarizes one poll of an app across its storefronts
+type pollResult struct {
+	Skipped   bool  // The circuit breakers of all storefronts are open
+	Cancelled bool  // The poller stopped before the poll finished
+	Succeeded bool  // At least one storefront was fetched
+	Err       error // Error of the last failed storefront
+	Fetched   int   // Reviews returned by the source
+	New       int   // Fetched reviews that were not stored before
+	Parse     ParseStats
+}
 
-	url := fmt.Sprintf(
-		"https://itunes.apple.com/us/rss/customerreviews/id=%s/sortBy=mostRecent/page=1/json",
-		appID,
-	)
+// pollApp fetches the storefronts of an app in parallel, each on a worker.
+// Storefronts whose circuit breaker is open are skipped, so a storefront
+// that keeps failing (e.g. a region-locked app) is paused on its own while
+// the others are polled as usual.
+func (p *Poller) pollApp(app models.App) pollResult {
+	var countries []string
+	for _, country := range feedCountries(app) {
+		if p.breakerFor(app.ID, country).allow() {
+			countries = append(countries, country)
+		}
+	}
+	if len(countries) == 0 {
+		return pollResult{Skipped: true} // All breakers open, skip quietly until a cool-down ends
+	}
 
-	reviews, err := p.fetchReviews(url, appID)
-	if err != nil {
-		return err
+	feeds := make([]feedResult, len(countries))
+	errs := make([]error, len(countries))
+	var wg sync.WaitGroup
+	for i, country := range countries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			feeds[i], errs[i] = p.pollStorefront(app, country)
+		}()
 	}
+	wg.Wait()
 
-	if len(reviews) == 0 {
-		p.logger.Printf("No reviews found for app %s", appID)
-		return nil
+	var result pollResult
+	for i, feed := range feeds {
+		result.Fetched += feed.Fetched
+		result.New += feed.New
+		result.Parse.add(feed.Parse)
+		switch {
+		case errors.Is(errs[i], ErrCancelled):
+			result.Cancelled = true
+		case errs[i] != nil:
+			result.Err = errs[i]
+		default:
+			result.Succeeded = true
+		}
 	}
 
-	if err := p.storage.SaveReviews(reviews); err != nil {
-		return err
+	// Stopping is not the app's fault, leave its interval alone
+	if !result.Cancelled && (result.Succeeded || result.Err == nil) {
+		p.adapt(app, result.New)
 	}
+	return result
+}
+
+// pollStorefront fetches one storefront of an app on a worker and records
+// the outcome in the storefront's circuit breaker
+func (p *Poller) pollStorefront(app models.App, country string) (feedResult, error) {
+	feed := feedLabel(app.ID, country)
+	breaker := p.breakerFor(app.ID, country)
 
-	p.logger.Printf("Stored %d reviews for app %s", len(reviews), appID)
-	return nil
+	select {
+	case p.workers <- struct{}{}:
+	case <-p.stopChan:
+		breaker.abortTrial()
+		return feedResult{}, ErrCancelled
+	}
+	defer func() { <-p.workers }()
+
+	result, err := p.fetchAndStore(app, country)
+	if errors.Is(err, ErrCancelled) {
+		// Stopping is not the storefront's fault, only hand back a trial poll
+		p.logger.Printf("Poll of app %s cancelled: %v", feed, err)
+		breaker.abortTrial()
+		return result, err
+	}
+	if err == nil {
+		p.logger.Printf("Successfully polled app %s", feed)
+		if previous := breaker.recordSuccess(); previous != BreakerClosed {
+			p.logger.Printf("Circuit breaker closed for app %s, polling resumed", feed)
+		}
+		return result, nil
+	}
+
+	p.logger.Printf("Error polling app %s: %v", feed, err)
+	if breaker.recordFailure() {
+		status := breaker.status()
+		p.logger.Printf("Circuit breaker opened for app %s after %d consecutive failures, pausing polls until %s: %v",
+			feed, status.ConsecutiveFailures, status.RetryAt.Format(time.RFC3339), err)
+	}
+	return result, err
 }
 
-// TODO: add error handling and retry logic
-func (p *Poller) fetchReviews(url, appID string) ([]models.Review, error) {
-	// Send HTTP request
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// breakerFor returns the circuit breaker of an app storefront, creating it
+// on first use
+func (p *Poller) breakerFor(appID, country string) *circuitBreaker {
+	p.breakersMu.Lock()
+	defer p.breakersMu.Unlock()
+
+	breakers, ok := p.breakers[appID]
+	if !ok {
+		breakers = make(map[string]*circuitBreaker)
+		p.breakers[appID] = breakers
+	}
+	breaker, ok := breakers[country]
+	if !ok {
+		breaker = newCircuitBreaker(p.breakerCfg)
+		breakers[country] = breaker
 	}
-	req.Header.Set("User-Agent", "AppReviewPoller/1.0")
+	return breaker
+}
 
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch reviews: %w", err)
+// breakerStatus returns the circuit breaker status of an app storefront
+// without creating its breaker; storefronts without one report closed
+func (p *Poller) breakerStatus(appID, country string) BreakerStatus {
+	p.breakersMu.Lock()
+	breaker, ok := p.breakers[appID][country]
+	p.breakersMu.Unlock()
+
+	if !ok {
+		return BreakerStatus{State: BreakerClosed}
 	}
-	defer resp.Body.Close()
+	return breaker.status()
+}
+
+// feedResult counts the reviews of one fetch of an app storefront
+type feedResult struct {
+	Fetched int
+	New     int
+	Parse   ParseStats
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+// fetchAndStore fetches the reviews of one app storefront from the app's
+// review source and stores them
+func (p *Poller) fetchAndStore(app models.App, country string) (feedResult, error) {
+	feed := feedLabel(app.ID, country)
+	p.logger.Printf("Fetching reviews for app %s", feed)
+	defer p.saveValidators()
+
+	source, ok := p.sources[app.StorePlatform()]
+	if !ok {
+		return feedResult{}, fmt.Errorf("no review source for platform %q", app.StorePlatform())
 	}
 
-	// Parse RSS feed
-	var feed RSSFeed
-	if err := json.NewDecoder(resp.Body).Decode(&feed); err != nil {
-		return nil, fmt.Errorf("failed to decode RSS feed: %w", err)
+	client := p.newSourceClient(feed)
+	var stats ParseStats
+	reviews, fetchErr := source.FetchReviews(client, FetchRequest{
+		App:     app,
+		Country: country,
+		Seen:    p.storage.HasReview,
+		Stats:   &stats,
+	})
+	if errors.Is(fetchErr, ErrNotModified) {
+		p.logger.Printf("No changes for app %s", feed)
+		return feedResult{}, nil
+	}
+	if stats.unexpectedSkips() > 0 {
+		p.logger.Printf("Parsed entries of app %s: %v", feed, stats)
 	}
+	p.checkSchema(app, country, source, stats, fetchErr)
 
-	// Convert to internal Review models
-	reviews := make([]models.Review, 0, len(feed.Feed.Entry))
-	now := time.Now()
+	if len(reviews) == 0 && fetchErr == nil {
+		p.logger.Printf("No reviews found for app %s", feed)
+		return feedResult{Parse: stats}, nil
+	}
 
-	for _, entry := range feed.Feed.Entry {
-		review, err := p.parseReviewEntry(entry, appID, now)
-		if err != nil {
-			p.logger.Printf("Warning: failed to parse review entry: %v", err)
-			continue // Skip malformed entries
+	result := feedResult{Fetched: len(reviews), Parse: stats}
+	for _, review := range reviews {
+		if !p.storage.HasReview(review.ID) {
+			result.New++
 		}
-		reviews = append(reviews, review)
+	}
+	// Stored reviews among the fetched ones mean the pages overlap what we have
+	overlaps := result.New < result.Fetched
+
+	// Keep whatever pages we managed to fetch, even if a later page failed
+	if len(reviews) > 0 {
+		if err := p.storage.SaveReviews(reviews); err != nil {
+			// Make sure the next poll downloads these pages again instead of getting a 304
+			client.forgetRecorded()
+			return feedResult{Fetched: result.Fetched, Parse: stats}, err
+		}
+		p.logger.Printf("Stored %d reviews for app %s", len(reviews), feed)
+	}
+
+	if fetchErr != nil {
+		// The pages after the failure were never seen, so the ones before must not be skipped next time
+		client.forgetRecorded()
+		return result, fetchErr
 	}
 
-	return reviews, nil
+	if overlaps {
+		p.detectRemoved(app, country, reviews)
+	}
+	return result, nil
 }
 
-func (p *Poller) parseReviewEntry(entry RSSEntry, appID string, fetchedAt time.Time) (models.Review, error) {
-	// Parse rating
-	rating, err := strconv.Atoi(entry.Rating.Label)
-	if err != nil {
-		return models.Review{}, fmt.Errorf("invalid rating: %w", err)
+// checkSchema feeds the outcome of a fetch into the schema monitor and logs
+// when the feed starts or stops drifting. A failed fetch only counts when
+// the response could not be decoded; an empty feed does not count for
+// sources that only serve recent reviews.
+func (p *Poller) checkSchema(app models.App, country string, source ReviewSource, stats ParseStats, fetchErr error) {
+	var drift *DriftStatus
+	var changed bool
+
+	var undecodable *decodeError
+	recent, _ := source.(recentOnlySource)
+	switch {
+	case errors.As(fetchErr, &undecodable) && !errors.Is(fetchErr, io.ErrUnexpectedEOF):
+		// A truncated body is a network problem, not a format change
+		drift, changed = p.schemas.undecodable(app.ID, country, undecodable, time.Now())
+	case stats.Seen > 0 || (fetchErr == nil && (recent == nil || !recent.recentOnly())):
+		drift, changed = p.schemas.observe(app.ID, country, stats, time.Now())
+	}
+
+	if !changed {
+		return
+	}
+	if drift != nil {
+		p.logger.Printf("Schema drift detected for app %s: %s", feedLabel(app.ID, country), drift.Reason)
+	} else {
+		p.logger.Printf("Schema drift cleared for app %s", feedLabel(app.ID, country))
+	}
+}
+
+// detectRemoved marks stored reviews of an app storefront as removed when
+// they are newer than the oldest fetched review, i.e. inside the window the
+// fetched pages cover, but no longer in the feed
+func (p *Poller) detectRemoved(app models.App, country string, fetched []models.Review) {
+	inFeed := make(map[string]bool, len(fetched))
+	oldest := fetched[0].SubmittedAt
+	for _, review := range fetched {
+		inFeed[review.ID] = true
+		if review.SubmittedAt.Before(oldest) {
+			oldest = review.SubmittedAt
+		}
 	}
 
-	// Parse submission timestamp
-	submittedAt, err := time.Parse(time.RFC3339, entry.Updated.Label)
+	// Look back a little further than needed, the window is applied exactly below
+	stored, err := p.storage.GetRecentReviews(app.ID, time.Since(oldest)+time.Minute)
 	if err != nil {
-		return models.Review{}, fmt.Errorf("invalid timestamp: %w", err)
+		p.logger.Printf("Warning: failed to check app %s for removed reviews: %v", feedLabel(app.ID, country), err)
+		return
 	}
 
-	// Generate a unique ID from the entry ID
-	// The entry.ID.Label looks like: "https://itunes.apple.com/us/review?id=12345&type=..."
-	// We'll use this as the unique identifier
-	reviewID := entry.ID.Label
+	var removed []string
+	for _, review := range stored {
+		if review.Country != country || review.RemovedAt != nil || inFeed[review.ID] {
+			continue
+		}
+		// Reviews as old as the oldest fetched one may continue on the next page
+		if !review.SubmittedAt.After(oldest) {
+			continue
+		}
+		removed = append(removed, review.ID)
+	}
+	if len(removed) == 0 {
+		return
+	}
 
-	return models.Review{
-		ID:          reviewID,
-		AppID:       appID,
-		Author:      entry.Author.Name.Label,
-		Content:     entry.Content.Label,
-		Rating:      rating,
-		SubmittedAt: submittedAt,
-		FetchedAt:   fetchedAt,
-	}, nil
+	if err := p.storage.MarkRemoved(removed, time.Now()); err != nil {
+		p.logger.Printf("Warning: failed to mark removed reviews of app %s: %v", feedLabel(app.ID, country), err)
+		return
+	}
+	p.logger.Printf("Marked %d reviews of app %s as removed from the store", len(removed), feedLabel(app.ID, country))
 }
 
+// saveValidators persists the feed validators, logging instead of failing the poll
+func (p *Poller) saveValidators() {
+	if err := p.validators.persist(); err != nil {
+		p.logger.Printf("Warning: failed to save feed validators: %v", err)
+	}
+}
+
+// feedCountries returns the storefronts to fetch for an app. Google Play does
+// not split reviews by storefront, so Android apps have a single feed with an
+// empty country.
+func feedCountries(app models.App) []string {
+	if app.StorePlatform() == models.PlatformAndroid {
+		return []string{""}
+	}
+	return app.StoreCountries()
+}
+
+// feedLabel names an app storefront in log messages, e.g. "123 (us)"
+func feedLabel(appID, country string) string {
+	if country == "" {
+		return appID
+	}
+	return fmt.Sprintf("%s (%s)", appID, country)
+}
+
+// Stop stops polling and cancels the polls that are still running, then
+// waits for them to return
 func (p *Poller) Stop() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -206,14 +578,57 @@ func (p *Poller) Stop() {
 		return // Not started, nothing to stop
 	}
 
+	p.stopScheduling()
+	p.cancel()
+	p.wg.Wait()
+	p.reset()
+}
+
+// Shutdown stops polling and lets running polls finish until ctx is done.
+// Polls still running then are cancelled and ctx's error is returned.
+func (p *Poller) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.started {
+		return nil
+	}
+
+	p.stopScheduling()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("cancelled running polls: %w", ctx.Err())
+		p.cancel()
+		<-done
+	}
+
+	p.cancel()
+	p.reset()
+	return err
+}
+
+// stopScheduling keeps new polls from starting
+func (p *Poller) stopScheduling() {
 	select {
 	case <-p.stopChan:
 		// Already closed
 	default:
 		close(p.stopChan)
 	}
+}
 
-	p.wg.Wait()
+// reset prepares a stopped poller for the next Start
+func (p *Poller) reset() {
 	p.started = false
 	p.stopChan = make(chan struct{})
+	p.ctx, p.cancel = context.WithCancel(context.Background())
 }
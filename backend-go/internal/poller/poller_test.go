@@ -3,24 +3,40 @@ package poller
 import (
 	"backend/internal/models"
 	"backend/internal/testutil"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
+// TestMain points the poller at a local fake iTunes server so tests never hit the real network
+func TestMain(m *testing.M) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"feed":{"entry":[]}}`))
+	}))
+	feedBaseURL = server.URL
+
+	code := m.Run()
+	server.Close()
+	os.Exit(code)
+}
 
 func TestNewPoller(t *testing.T) {
 	interval := 100 * time.Millisecond
-	appIDs := []string{"app1", "app2"}
+	apps := []models.App{{ID: "app1"}, {ID: "app2"}}
 	logger := log.New(io.Discard, "", 0)
 	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, appIDs, interval)
+	poller := NewPoller(storage, logger, apps, interval)
 
 	if poller == nil {
 		t.Fatal("NewPoller returned nil")
@@ -28,8 +44,8 @@ func TestNewPoller(t *testing.T) {
 	if poller.pollInterval != interval {
 		t.Errorf("Expected poll interval %v, got %v", interval, poller.pollInterval)
 	}
-	if len(poller.appIDs) != len(appIDs) {
-		t.Errorf("Expected %d appIDs, got %d", len(appIDs), len(poller.appIDs))
+	if len(poller.apps) != len(apps) {
+		t.Errorf("Expected %d apps, got %d", len(apps), len(poller.apps))
 	}
 	if poller.stopChan == nil {
 		t.Error("stopChan should be initialized")
@@ -39,22 +55,22 @@ func TestNewPoller(t *testing.T) {
 func TestNewPoller_EmptyAppIDs(t *testing.T) {
 	logger := log.New(io.Discard, "", 0)
 	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, []string{}, 100*time.Millisecond)
+	poller := NewPoller(storage, logger, []models.App{}, 100*time.Millisecond)
 
 	if poller == nil {
 		t.Fatal("NewPoller returned nil")
 	}
-	if len(poller.appIDs) != 0 {
-		t.Errorf("Expected 0 appIDs, got %d", len(poller.appIDs))
+	if len(poller.apps) != 0 {
+		t.Errorf("Expected 0 apps, got %d", len(poller.apps))
 	}
 }
 
 func TestPoller_StartAndStop(t *testing.T) {
 	interval := 100 * time.Millisecond
 	logger := log.New(io.Discard, "", 0)
-	appIDs := []string{"app1"}
+	apps := []models.App{{ID: "app1"}}
 	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, appIDs, interval)
+	poller := NewPoller(storage, logger, apps, interval)
 
 	poller.Start()
 	time.Sleep(50 * time.Millisecond)
@@ -77,9 +93,9 @@ func TestPoller_StartAndStop(t *testing.T) {
 func TestPoller_ImmediatePollOnStart(t *testing.T) {
 	var buf testutil.SafeBuffer
 	logger := log.New(&buf, "", 0)
-	appIDs := []string{"app1", "app2"}
+	apps := []models.App{{ID: "app1"}, {ID: "app2"}}
 	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, appIDs, time.Second)
+	poller := NewPoller(storage, logger, apps, time.Second)
 
 	poller.Start()
 	time.Sleep(200 * time.Millisecond)
@@ -102,9 +118,9 @@ func TestPoller_PeriodicPolling(t *testing.T) {
 	var buf testutil.SafeBuffer
 	logger := log.New(&buf, "", 0)
 	interval := 100 * time.Millisecond
-	appIDs := []string{"app1"}
+	apps := []models.App{{ID: "app1"}}
 	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, appIDs, interval)
+	poller := NewPoller(storage, logger, apps, interval)
 
 	poller.Start()
 	// Wait for multiple poll cycles
@@ -112,7 +128,7 @@ func TestPoller_PeriodicPolling(t *testing.T) {
 	poller.Stop()
 
 	logOutput := buf.String()
-	count := strings.Count(logOutput, "Polling all apps concurrently...")
+	count := strings.Count(logOutput, "Fetching reviews for app app1")
 
 	// Should have at least 3 polls: immediate + 2-3 periodic
 	if count < 3 {
@@ -123,9 +139,9 @@ func TestPoller_PeriodicPolling(t *testing.T) {
 func TestPoller_PollsAllApps(t *testing.T) {
 	var buf testutil.SafeBuffer
 	logger := log.New(&buf, "", 0)
-	appIDs := []string{"app1", "app2", "app3"}
+	apps := []models.App{{ID: "app1"}, {ID: "app2"}, {ID: "app3"}}
 	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, appIDs, time.Second)
+	poller := NewPoller(storage, logger, apps, time.Second)
 
 	poller.Start()
 	time.Sleep(100 * time.Millisecond)
@@ -134,13 +150,13 @@ func TestPoller_PollsAllApps(t *testing.T) {
 	logOutput := buf.String()
 
 	// Verify all apps were fetched
-	for _, appID := range appIDs {
-		expectedMsg := "Fetching reviews for app " + appID
+	for _, app := range apps {
+		expectedMsg := "Fetching reviews for app " + app.ID
 		if !strings.Contains(logOutput, expectedMsg) {
 			t.Errorf("Expected to find '%s' in log output", expectedMsg)
 		}
 
-		successMsg := "Successfully polled app " + appID
+		successMsg := "Successfully polled app " + app.ID
 		if !strings.Contains(logOutput, successMsg) {
 			t.Errorf("Expected to find '%s' in log output", successMsg)
 		}
@@ -156,7 +172,7 @@ func TestPoller_EmptyAppIDsNoCrash(t *testing.T) {
 	var buf testutil.SafeBuffer
 	logger := log.New(&buf, "", 0)
 	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, []string{}, 100*time.Millisecond)
+	poller := NewPoller(storage, logger, []models.App{}, 100*time.Millisecond)
 
 	poller.Start()
 	time.Sleep(150 * time.Millisecond)
@@ -173,9 +189,9 @@ func TestPoller_MultipleStartCallsSafe(t *testing.T) {
 	var buf testutil.SafeBuffer
 	logger := log.New(&buf, "", 0)
 	interval := 100 * time.Millisecond
-	appIDs := []string{"app1"}
+	apps := []models.App{{ID: "app1"}}
 	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, appIDs, interval)
+	poller := NewPoller(storage, logger, apps, interval)
 
 	// Start multiple times - should only start once
 	poller.Start()
@@ -197,9 +213,9 @@ func TestPoller_MultipleStartCallsSafe(t *testing.T) {
 func TestPoller_StopWithoutStart(t *testing.T) {
 	logger := log.New(io.Discard, "", 0)
 	interval := 100 * time.Millisecond
-	appIDs := []string{"app1"}
+	apps := []models.App{{ID: "app1"}}
 	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, appIDs, interval)
+	poller := NewPoller(storage, logger, apps, interval)
 
 	poller.Stop()
 }
@@ -207,9 +223,9 @@ func TestPoller_StopWithoutStart(t *testing.T) {
 func TestPoller_MultipleStopCallsSafe(t *testing.T) {
 	logger := log.New(io.Discard, "", 0)
 	interval := 100 * time.Millisecond
-	appIDs := []string{"app1"}
+	apps := []models.App{{ID: "app1"}}
 	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, appIDs, interval)
+	poller := NewPoller(storage, logger, apps, interval)
 
 	poller.Start()
 	time.Sleep(50 * time.Millisecond)
@@ -220,9 +236,9 @@ func TestPoller_MultipleStopCallsSafe(t *testing.T) {
 func TestPoller_RestartAfterStop(t *testing.T) {
 	var buf testutil.SafeBuffer
 	logger := log.New(&buf, "", 0)
-	appIDs := []string{"app1"}
+	apps := []models.App{{ID: "app1"}}
 	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, appIDs, 100*time.Millisecond)
+	poller := NewPoller(storage, logger, apps, 100*time.Millisecond)
 
 	poller.Start()
 	time.Sleep(150 * time.Millisecond)
@@ -253,9 +269,9 @@ func TestPoller_ConcurrentAppPolling(t *testing.T) {
 	var buf testutil.SafeBuffer
 	logger := log.New(&buf, "", 0)
 	// Create many apps to increase likelihood of concurrent execution
-	appIDs := []string{"app1", "app2", "app3", "app4", "app5"}
+	apps := []models.App{{ID: "app1"}, {ID: "app2"}, {ID: "app3"}, {ID: "app4"}, {ID: "app5"}}
 	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, appIDs, time.Second)
+	poller := NewPoller(storage, logger, apps, time.Second)
 
 	start := time.Now()
 	poller.Start()
@@ -266,9 +282,9 @@ func TestPoller_ConcurrentAppPolling(t *testing.T) {
 	logOutput := buf.String()
 	
 	// All apps should be polled
-	for _, appID := range appIDs {
-		if !strings.Contains(logOutput, "Fetching reviews for app "+appID) {
-			t.Errorf("App %s was not polled", appID)
+	for _, app := range apps {
+		if !strings.Contains(logOutput, "Fetching reviews for app "+app.ID) {
+			t.Errorf("App %s was not polled", app.ID)
 		}
 	}
 	
@@ -280,14 +296,252 @@ func TestPoller_ConcurrentAppPolling(t *testing.T) {
 	}
 }
 
+func TestPoller_PollsEveryAppCountryPair(t *testing.T) {
+	var mu sync.Mutex
+	requested := make(map[string]bool)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested[r.URL.Path] = true
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"feed":{"entry":[]}}`))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	logger := log.New(io.Discard, "", 0)
+	storage := testutil.NewMockStorage()
+	apps := []models.App{
+		{ID: "app1", Countries: []string{"us", "DE"}},
+		{ID: "app2"},
+	}
+	poller := NewPoller(storage, logger, apps, time.Second)
+
+	poller.pollAllAppsConcurrently()
+
+	expectedPaths := []string{
+		"/us/rss/customerreviews/id=app1/sortBy=mostRecent/page=1/json",
+		"/de/rss/customerreviews/id=app1/sortBy=mostRecent/page=1/json",
+		"/us/rss/customerreviews/id=app2/sortBy=mostRecent/page=1/json",
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(requested) != len(expectedPaths) {
+		t.Errorf("Expected %d feed requests, got %d: %v", len(expectedPaths), len(requested), requested)
+	}
+	for _, path := range expectedPaths {
+		if !requested[path] {
+			t.Errorf("Expected feed request for %s", path)
+		}
+	}
+}
+
+// Pagination Tests
+
+// feedPageJSON renders an iTunes feed page with one review per ID and an optional rel="next" link
+func feedPageJSON(ids []string, next string) string {
+	entries := make([]string, 0, len(ids))
+	for _, id := range ids {
+		entries = append(entries, `{"author":{"name":{"label":"User"}},"content":{"label":"Text"},`+
+			`"im:rating":{"label":"4"},"updated":{"label":"2023-01-15T10:30:00Z"},"id":{"label":"`+id+`"}}`)
+	}
+
+	links := `{"attributes":{"rel":"self","href":"self"}}`
+	if next != "" {
+		links += `,{"attributes":{"rel":"next","href":"` + next + `"}}`
+	}
+
+	return `{"feed":{"entry":[` + strings.Join(entries, ",") + `],"link":[` + links + `]}}`
+}
+
+func TestPoller_fetchAndStoreFollowsNextLinks(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(r.URL.Path, "page=1"):
+			// iTunes advertises the next page in its XML form
+			w.Write([]byte(feedPageJSON([]string{"r1", "r2"}, server.URL+"/us/rss/customerreviews/page=2/id=123/sortby=mostrecent/xml?urlDesc=x")))
+		case strings.Contains(r.URL.Path, "page=2"):
+			w.Write([]byte(feedPageJSON([]string{"r3", "r4"}, "/us/rss/customerreviews/page=3/id=123/sortby=mostrecent/json")))
+		default:
+			w.Write([]byte(feedPageJSON([]string{"r5"}, "")))
+		}
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	storage := testutil.NewMockStorage()
+	poller := NewPoller(storage, log.New(io.Discard, "", 0), []models.App{{ID: "123"}}, time.Second)
+
+	if _, err := poller.fetchAndStore(models.App{ID: "123"}, "us"); err != nil {
+		t.Fatalf("fetchAndStore failed: %v", err)
+	}
+
+	if storage.GetSavedReviewCount() != 5 {
+		t.Errorf("Expected 5 reviews stored from 3 pages, got %d", storage.GetSavedReviewCount())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	expectedPaths := []string{
+		"/us/rss/customerreviews/id=123/sortBy=mostRecent/page=1/json",
+		"/us/rss/customerreviews/page=2/id=123/sortby=mostrecent/json",
+		"/us/rss/customerreviews/page=3/id=123/sortby=mostrecent/json",
+	}
+	if len(requested) != len(expectedPaths) {
+		t.Fatalf("Expected %d page requests, got %d: %v", len(expectedPaths), len(requested), requested)
+	}
+	for i, path := range expectedPaths {
+		if requested[i] != path {
+			t.Errorf("Expected request %d to be %s, got %s", i+1, path, requested[i])
+		}
+	}
+}
+
+func TestPoller_fetchAndStoreStopsAtStoredReviews(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		page := requests
+		mu.Unlock()
+
+		ids := []string{fmt.Sprintf("r%d-a", page), fmt.Sprintf("r%d-b", page)}
+		next := fmt.Sprintf("%s/us/rss/customerreviews/page=%d/id=123/json", server.URL, page+1)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(feedPageJSON(ids, next)))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	storage := testutil.NewMockStorage()
+	// Page 2 contains a review we already have
+	storage.SaveReviews([]models.Review{{ID: "r2-b", AppID: "123"}})
+
+	poller := NewPoller(storage, log.New(io.Discard, "", 0), []models.App{{ID: "123"}}, time.Second)
+
+	if _, err := poller.fetchAndStore(models.App{ID: "123"}, "us"); err != nil {
+		t.Fatalf("fetchAndStore failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if requests != 2 {
+		t.Errorf("Expected polling to stop after page 2, got %d requests", requests)
+	}
+	if storage.GetSavedReviewCount() != 4 {
+		t.Errorf("Expected 4 reviews stored, got %d", storage.GetSavedReviewCount())
+	}
+}
+
+func TestPoller_fetchAndStoreStopsAtPageLimit(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		page := requests
+		mu.Unlock()
+
+		next := fmt.Sprintf("%s/us/rss/customerreviews/page=%d/id=123/json", server.URL, page+1)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(feedPageJSON([]string{fmt.Sprintf("r%d", page)}, next)))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	storage := testutil.NewMockStorage()
+	poller := NewPoller(storage, log.New(io.Discard, "", 0), []models.App{{ID: "123"}}, time.Second)
+
+	if _, err := poller.fetchAndStore(models.App{ID: "123"}, "us"); err != nil {
+		t.Fatalf("fetchAndStore failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if requests != maxFeedPages {
+		t.Errorf("Expected %d page requests, got %d", maxFeedPages, requests)
+	}
+}
+
+func TestPoller_fetchAndStoreKeepsPagesBeforeError(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "page=2") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(feedPageJSON([]string{"r1", "r2"}, server.URL+"/us/rss/customerreviews/page=2/id=123/json")))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	storage := testutil.NewMockStorage()
+	poller := NewPoller(storage, log.New(io.Discard, "", 0), []models.App{{ID: "123"}}, time.Second)
+
+	_, err := poller.fetchAndStore(models.App{ID: "123"}, "us")
+	if err == nil {
+		t.Fatal("Expected error for failed page 2, got nil")
+	}
+	if !strings.Contains(err.Error(), "failed to fetch page 2") {
+		t.Errorf("Expected page error, got: %v", err)
+	}
+	if storage.GetSavedReviewCount() != 2 {
+		t.Errorf("Expected reviews from page 1 to be stored, got %d", storage.GetSavedReviewCount())
+	}
+}
+
 // HTTP Request Tests
 
+// fetchFeedPage fetches a single feed page through the poller's iTunes source
+func fetchFeedPage(p *Poller, url, appID, country string) ([]models.Review, string, error) {
+	source := p.sources[models.PlatformIOS].(*ITunesSource)
+	return source.fetchPage(p.newSourceClient("test"), url, appID, country, &ParseStats{})
+}
+
 func TestPoller_fetchReviewsSuccess(t *testing.T) {
 	// Create mock RSS feed response
 	mockFeed := RSSFeed{
-		Feed: struct {
-			Entry []RSSEntry `json:"entry"`
-		}{
+		Feed: RSSFeedBody{
 			Entry: []RSSEntry{
 				{
 					Author: struct {
@@ -332,9 +586,9 @@ func TestPoller_fetchReviewsSuccess(t *testing.T) {
 
 	logger := log.New(io.Discard, "", 0)
 	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, []string{}, time.Second)
+	poller := NewPoller(storage, logger, []models.App{}, time.Second)
 
-	reviews, err := poller.fetchReviews(server.URL, "123")
+	reviews, _, err := fetchFeedPage(poller, server.URL, "123", "us")
 	if err != nil {
 		t.Fatalf("fetchReviews failed: %v", err)
 	}
@@ -347,6 +601,9 @@ func TestPoller_fetchReviewsSuccess(t *testing.T) {
 	if review.AppID != "123" {
 		t.Errorf("Expected AppID '123', got '%s'", review.AppID)
 	}
+	if review.Country != "us" {
+		t.Errorf("Expected Country 'us', got '%s'", review.Country)
+	}
 	if review.Author != "John Doe" {
 		t.Errorf("Expected Author 'John Doe', got '%s'", review.Author)
 	}
@@ -367,9 +624,9 @@ func TestPoller_fetchReviewsHTTPError(t *testing.T) {
 
 	logger := log.New(io.Discard, "", 0)
 	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, []string{}, time.Second)
+	poller := NewPoller(storage, logger, []models.App{}, time.Second, WithRetry(fastRetry))
 
-	_, err := poller.fetchReviews(server.URL, "123")
+	_, _, err := fetchFeedPage(poller, server.URL, "123", "us")
 	if err == nil {
 		t.Fatal("Expected error for HTTP 500, got nil")
 	}
@@ -388,9 +645,9 @@ func TestPoller_fetchReviewsInvalidJSON(t *testing.T) {
 
 	logger := log.New(io.Discard, "", 0)
 	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, []string{}, time.Second)
+	poller := NewPoller(storage, logger, []models.App{}, time.Second)
 
-	_, err := poller.fetchReviews(server.URL, "123")
+	_, _, err := fetchFeedPage(poller, server.URL, "123", "us")
 	if err == nil {
 		t.Fatal("Expected error for invalid JSON, got nil")
 	}
@@ -402,9 +659,7 @@ func TestPoller_fetchReviewsInvalidJSON(t *testing.T) {
 func TestPoller_fetchReviewsEmptyFeed(t *testing.T) {
 	// Create mock empty RSS feed response
 	mockFeed := RSSFeed{
-		Feed: struct {
-			Entry []RSSEntry `json:"entry"`
-		}{
+		Feed: RSSFeedBody{
 			Entry: []RSSEntry{},
 		},
 	}
@@ -417,9 +672,9 @@ func TestPoller_fetchReviewsEmptyFeed(t *testing.T) {
 
 	logger := log.New(io.Discard, "", 0)
 	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, []string{}, time.Second)
+	poller := NewPoller(storage, logger, []models.App{}, time.Second)
 
-	reviews, err := poller.fetchReviews(server.URL, "123")
+	reviews, _, err := fetchFeedPage(poller, server.URL, "123", "us")
 	if err != nil {
 		t.Fatalf("fetchReviews failed: %v", err)
 	}
@@ -430,10 +685,6 @@ func TestPoller_fetchReviewsEmptyFeed(t *testing.T) {
 }
 
 func TestPoller_parseReviewEntry(t *testing.T) {
-	logger := log.New(io.Discard, "", 0)
-	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, []string{}, time.Second)
-
 	entry := RSSEntry{
 		Author: struct {
 			Name struct {
@@ -459,7 +710,7 @@ func TestPoller_parseReviewEntry(t *testing.T) {
 	}
 
 	fetchedAt := time.Now()
-	review, err := poller.parseReviewEntry(entry, "789", fetchedAt)
+	review, err := parseITunesEntry(entry, "789", "us", fetchedAt)
 	if err != nil {
 		t.Fatalf("parseReviewEntry failed: %v", err)
 	}
@@ -470,6 +721,9 @@ func TestPoller_parseReviewEntry(t *testing.T) {
 	if review.AppID != "789" {
 		t.Errorf("Expected AppID '789', got '%s'", review.AppID)
 	}
+	if review.Platform != models.PlatformIOS {
+		t.Errorf("Expected Platform %q, got %q", models.PlatformIOS, review.Platform)
+	}
 	if review.Author != "Jane Smith" {
 		t.Errorf("Expected Author 'Jane Smith', got '%s'", review.Author)
 	}
@@ -489,11 +743,44 @@ func TestPoller_parseReviewEntry(t *testing.T) {
 	}
 }
 
-func TestPoller_parseReviewEntryInvalidRating(t *testing.T) {
-	logger := log.New(io.Discard, "", 0)
-	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, []string{}, time.Second)
+func TestPoller_parseReviewEntryMetadata(t *testing.T) {
+	var entry RSSEntry
+	err := json.Unmarshal([]byte(`{
+		"author": {"name": {"label": "Jane Smith"}},
+		"updated": {"label": "2023-02-20T15:45:30Z"},
+		"im:rating": {"label": "4"},
+		"im:version": {"label": "4.2.0"},
+		"id": {"label": "456"},
+		"title": {"label": "Works well"},
+		"content": {"label": "Amazing functionality!"},
+		"link": {"attributes": {"rel": "related", "href": "https://itunes.apple.com/us/review?id=789&type=Purple%20Software"}},
+		"im:voteSum": {"label": "3"},
+		"im:voteCount": {"label": "5"}
+	}`), &entry)
+	if err != nil {
+		t.Fatalf("Failed to decode entry: %v", err)
+	}
 
+	review, err := parseITunesEntry(entry, "789", "us", time.Now())
+	if err != nil {
+		t.Fatalf("parseReviewEntry failed: %v", err)
+	}
+
+	if review.Title != "Works well" {
+		t.Errorf("Expected Title 'Works well', got '%s'", review.Title)
+	}
+	if review.Version != "4.2.0" {
+		t.Errorf("Expected Version '4.2.0', got '%s'", review.Version)
+	}
+	if review.VoteSum != 3 || review.VoteCount != 5 {
+		t.Errorf("Expected votes 3/5, got %d/%d", review.VoteSum, review.VoteCount)
+	}
+	if review.Link != "https://itunes.apple.com/us/review?id=789&type=Purple%20Software" {
+		t.Errorf("Expected review link, got '%s'", review.Link)
+	}
+}
+
+func TestPoller_parseReviewEntryInvalidRating(t *testing.T) {
 	entry := RSSEntry{
 		Rating: struct {
 			Label string `json:"label"`
@@ -503,7 +790,7 @@ func TestPoller_parseReviewEntryInvalidRating(t *testing.T) {
 		}{Label: "2023-02-20T15:45:30Z"},
 	}
 
-	_, err := poller.parseReviewEntry(entry, "789", time.Now())
+	_, err := parseITunesEntry(entry, "789", "us", time.Now())
 	if err == nil {
 		t.Fatal("Expected error for invalid rating, got nil")
 	}
@@ -513,10 +800,6 @@ func TestPoller_parseReviewEntryInvalidRating(t *testing.T) {
 }
 
 func TestPoller_parseReviewEntryInvalidTimestamp(t *testing.T) {
-	logger := log.New(io.Discard, "", 0)
-	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, []string{}, time.Second)
-
 	entry := RSSEntry{
 		Rating: struct {
 			Label string `json:"label"`
@@ -526,7 +809,7 @@ func TestPoller_parseReviewEntryInvalidTimestamp(t *testing.T) {
 		}{Label: "invalid-timestamp"},
 	}
 
-	_, err := poller.parseReviewEntry(entry, "789", time.Now())
+	_, err := parseITunesEntry(entry, "789", "us", time.Now())
 	if err == nil {
 		t.Fatal("Expected error for invalid timestamp, got nil")
 	}
@@ -541,9 +824,7 @@ func TestPoller_fetchReviewsMalformedEntries(t *testing.T) {
 
 	// Create mock RSS feed with one valid and one invalid entry
 	mockFeed := RSSFeed{
-		Feed: struct {
-			Entry []RSSEntry `json:"entry"`
-		}{
+		Feed: RSSFeedBody{
 			Entry: []RSSEntry{
 				{
 					Author: struct {
@@ -588,9 +869,9 @@ func TestPoller_fetchReviewsMalformedEntries(t *testing.T) {
 	defer server.Close()
 
 	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, []string{}, time.Second)
+	poller := NewPoller(storage, logger, []models.App{}, time.Second)
 
-	reviews, err := poller.fetchReviews(server.URL, "123")
+	reviews, _, err := fetchFeedPage(poller, server.URL, "123", "us")
 	if err != nil {
 		t.Fatalf("fetchReviews failed: %v", err)
 	}
@@ -610,7 +891,7 @@ func TestPoller_fetchReviewsMalformedEntries(t *testing.T) {
 func TestNewPoller_HTTPClientConfiguration(t *testing.T) {
 	logger := log.New(io.Discard, "", 0)
 	storage := testutil.NewMockStorage()
-	poller := NewPoller(storage, logger, []string{"123"}, time.Second)
+	poller := NewPoller(storage, logger, []models.App{{ID: "123"}}, time.Second)
 
 	if poller.client == nil {
 		t.Fatal("HTTP client should be initialized")
@@ -620,6 +901,44 @@ func TestNewPoller_HTTPClientConfiguration(t *testing.T) {
 	}
 }
 
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNewPoller_CustomTransport(t *testing.T) {
+	var userAgent string
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		userAgent = req.Header.Get("User-Agent")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(feedPageJSON([]string{"r1"}, ""))),
+			Request:    req,
+		}, nil
+	})
+
+	shared := &http.Client{Timeout: 5 * time.Second}
+	storage := testutil.NewMockStorage()
+	poller := NewPoller(storage, log.New(io.Discard, "", 0), nil, time.Second,
+		WithHTTPClient(shared), WithRoundTripper(transport), WithUserAgent("ReviewBot/2.0"))
+
+	if _, err := poller.fetchAndStore(models.App{ID: "123"}, "us"); err != nil {
+		t.Fatalf("fetchAndStore failed: %v", err)
+	}
+	if storage.GetSavedReviewCount() != 1 {
+		t.Errorf("Expected the review served by the transport to be stored, got %d", storage.GetSavedReviewCount())
+	}
+	if userAgent != "ReviewBot/2.0" {
+		t.Errorf("Expected the configured User-Agent, got %q", userAgent)
+	}
+	if poller.client.Timeout != 5*time.Second || shared.Transport != nil {
+		t.Errorf("Expected the poller to use a copy of the shared client")
+	}
+}
+
 // Storage Integration Tests
 
 func TestPoller_SaveReviewsToStorage(t *testing.T) {
@@ -782,4 +1101,229 @@ func TestPoller_MockStorageInterface(t *testing.T) {
 	if updatedReview.Author != "Updated User" {
 		t.Errorf("Expected updated Author 'Updated User', got '%s'", updatedReview.Author)
 	}
-}
\ No newline at end of file
+}
+
+func TestPoller_fetchAndStoreMarksRemovedReviews(t *testing.T) {
+	base := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	entry := func(id string, age time.Duration) string {
+		return `{"author":{"name":{"label":"User"}},"content":{"label":"Text"},"im:rating":{"label":"4"},` +
+			`"updated":{"label":"` + base.Add(-age).Format(time.RFC3339) + `"},"id":{"label":"` + id + `"}}`
+	}
+
+	// r3 disappeared from the feed, r5 is older than anything on the page
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"feed":{"entry":[` + entry("r1", time.Hour) + `,` + entry("r2", 2*time.Hour) + `,` +
+			entry("r4", 4*time.Hour) + `]}}`))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	storage := testutil.NewMockStorage()
+	storage.SaveReviews([]models.Review{
+		{ID: "r2", AppID: "123", Country: "us", SubmittedAt: base.Add(-2 * time.Hour)},
+		{ID: "r3", AppID: "123", Country: "us", SubmittedAt: base.Add(-3 * time.Hour)},
+		{ID: "r3-de", AppID: "123", Country: "de", SubmittedAt: base.Add(-3 * time.Hour)},
+		{ID: "r4", AppID: "123", Country: "us", SubmittedAt: base.Add(-4 * time.Hour)},
+		{ID: "r5", AppID: "123", Country: "us", SubmittedAt: base.Add(-5 * time.Hour)},
+	})
+
+	var buf testutil.SafeBuffer
+	poller := NewPoller(storage, log.New(&buf, "", 0), []models.App{{ID: "123"}}, time.Second)
+
+	if _, err := poller.fetchAndStore(models.App{ID: "123"}, "us"); err != nil {
+		t.Fatalf("fetchAndStore failed: %v", err)
+	}
+
+	for id, removed := range map[string]bool{"r1": false, "r2": false, "r3": true, "r3-de": false, "r4": false, "r5": false} {
+		review, _ := storage.GetReview(id)
+		if (review.RemovedAt != nil) != removed {
+			t.Errorf("Review %s: expected removed=%v, got removed_at %v", id, removed, review.RemovedAt)
+		}
+	}
+	if !strings.Contains(buf.String(), "Marked 1 reviews of app 123 (us) as removed") {
+		t.Errorf("Expected removal log message, got: %s", buf.String())
+	}
+}
+
+func TestPoller_fetchAndStoreNoRemovalWithoutOverlap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(feedPageJSON([]string{"new1", "new2"}, "")))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	// Newer than the fetched page, but without an overlap we cannot tell what the feed covers
+	storage := testutil.NewMockStorage()
+	storage.SaveReviews([]models.Review{{ID: "old", AppID: "123", Country: "us", SubmittedAt: time.Now()}})
+
+	poller := NewPoller(storage, log.New(io.Discard, "", 0), []models.App{{ID: "123"}}, time.Second)
+	if _, err := poller.fetchAndStore(models.App{ID: "123"}, "us"); err != nil {
+		t.Fatalf("fetchAndStore failed: %v", err)
+	}
+
+	if review, _ := storage.GetReview("old"); review.RemovedAt != nil {
+		t.Error("Expected no review to be marked removed without overlapping pages")
+	}
+}
+
+// Cancellation Tests
+
+// startBlockedPoll starts a poller whose only app is stuck in a feed request
+// that is answered after delay, or never when delay is zero
+func startBlockedPoll(t *testing.T, delay time.Duration) (*Poller, *testutil.SafeBuffer, *testutil.MockStorage) {
+	t.Helper()
+
+	requested := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case requested <- struct{}{}:
+		default:
+		}
+		if delay == 0 {
+			<-r.Context().Done()
+			return
+		}
+		time.Sleep(delay)
+		w.Write([]byte(feedPageJSON([]string{"r1"}, "")))
+	}))
+	t.Cleanup(server.Close)
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	t.Cleanup(func() { feedBaseURL = originalBaseURL })
+
+	var buf testutil.SafeBuffer
+	storage := testutil.NewMockStorage()
+	apps := []models.App{{ID: "123", Interval: models.Duration(time.Hour)}}
+	poller := NewPoller(storage, log.New(&buf, "", 0), apps, time.Hour, WithJitter(0))
+	poller.Start()
+
+	select {
+	case <-requested:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Poll did not start")
+	}
+	return poller, &buf, storage
+}
+
+func TestPoller_StopCancelsRunningRequests(t *testing.T) {
+	poller, buf, _ := startBlockedPoll(t, 0)
+
+	start := time.Now()
+	poller.Stop()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Stop to cancel the running request, took %v", elapsed)
+	}
+
+	logOutput := buf.String()
+	if !strings.Contains(logOutput, "Poll of app 123 (us) cancelled") {
+		t.Errorf("Expected the poll to be reported as cancelled, got: %s", logOutput)
+	}
+	if strings.Contains(logOutput, "Error polling app") {
+		t.Errorf("Expected cancellation not to be reported as a fetch error, got: %s", logOutput)
+	}
+
+	status := poller.Status()[0]
+	if status.ConsecutiveFailures != 0 || status.LastError != "" || status.LastAttempt != nil {
+		t.Errorf("Expected the cancelled poll not to be recorded as a failure, got %+v", status)
+	}
+}
+
+// stalledBody returns part of a feed and then blocks until ctx is done
+type stalledBody struct {
+	ctx     context.Context
+	reading chan struct{}
+	sent    bool
+}
+
+func (b *stalledBody) Read(data []byte) (int, error) {
+	if !b.sent {
+		b.sent = true
+		close(b.reading)
+		return copy(data, `{"feed":{"entry":[`), nil
+	}
+	<-b.ctx.Done()
+	return 0, b.ctx.Err()
+}
+
+func (b *stalledBody) Close() error { return nil }
+
+func TestPoller_StopDuringBodyReadIsCancelled(t *testing.T) {
+	reading := make(chan struct{})
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       &stalledBody{ctx: req.Context(), reading: reading},
+			Request:    req,
+		}, nil
+	})
+
+	var buf testutil.SafeBuffer
+	apps := []models.App{{ID: "123", Interval: models.Duration(time.Hour)}}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(&buf, "", 0), apps, time.Hour,
+		WithJitter(0), WithRoundTripper(transport))
+	poller.Start()
+
+	select {
+	case <-reading:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Poll did not start")
+	}
+	poller.Stop()
+
+	logOutput := buf.String()
+	if !strings.Contains(logOutput, "Poll of app 123 (us) cancelled") || strings.Contains(logOutput, "Error polling app") {
+		t.Errorf("Expected the cut off body to be reported as cancelled, got: %s", logOutput)
+	}
+
+	status := poller.Status()[0]
+	if status.ConsecutiveFailures != 0 || status.LastError != "" || status.Breakers[0].ConsecutiveFailures != 0 {
+		t.Errorf("Expected the cancelled poll not to be recorded as a failure, got %+v", status)
+	}
+}
+
+func TestPoller_ShutdownWaitsForRunningPolls(t *testing.T) {
+	poller, _, storage := startBlockedPoll(t, 100*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := poller.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	if storage.GetSavedReviewCount() != 1 {
+		t.Errorf("Expected the running poll to finish and store its review, got %d reviews", storage.GetSavedReviewCount())
+	}
+}
+
+func TestPoller_ShutdownDeadlineCancelsRunningPolls(t *testing.T) {
+	poller, buf, _ := startBlockedPoll(t, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := poller.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Shutdown to return shortly after the deadline, took %v", elapsed)
+	}
+	if !strings.Contains(buf.String(), "Poll of app 123 (us) cancelled") {
+		t.Errorf("Expected the poll to be reported as cancelled, got: %s", buf.String())
+	}
+
+	// The poller can be started again after a shutdown
+	poller.Start()
+	poller.Stop()
+}
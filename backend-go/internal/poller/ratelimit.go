@@ -0,0 +1,99 @@
+package poller
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimitConfig controls the request rate shared by all feed fetches
+type RateLimitConfig struct {
+	RequestsPerSecond float64 // Sustained request rate (0 disables rate limiting)
+	Burst             int     // Requests that may be sent back to back before throttling kicks in
+}
+
+// DefaultRateLimitConfig returns the rate limit used when none is configured
+func DefaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		RequestsPerSecond: 5,
+		Burst:             10,
+	}
+}
+
+// DefaultMaxConcurrency is the number of apps polled in parallel when not configured
+const DefaultMaxConcurrency = 10
+
+// tokenBucket is a token bucket rate limiter. Tokens refill continuously at
+// rate per second up to burst; each request takes one token.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// newTokenBucket returns a bucket that starts full, or nil when rate limiting is disabled
+func newTokenBucket(config RateLimitConfig) *tokenBucket {
+	if config.RequestsPerSecond <= 0 {
+		return nil
+	}
+
+	burst := float64(max(config.Burst, 1))
+	return &tokenBucket{
+		rate:   config.RequestsPerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// reserve takes a token and returns how long the caller has to wait before using it.
+// Tokens may go negative, which queues callers behind each other.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token that was never used
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.burst, b.tokens+1)
+}
+
+// wait blocks until a token is available. It returns false if stop is closed first.
+// A nil bucket never blocks.
+func (b *tokenBucket) wait(stop <-chan struct{}) bool {
+	if b == nil {
+		return true
+	}
+
+	delay := b.reserve()
+	if delay <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-stop:
+		b.cancel()
+		return false
+	}
+}
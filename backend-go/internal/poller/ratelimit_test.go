@@ -0,0 +1,174 @@
+package poller
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+func newTestBucket(config RateLimitConfig) (*tokenBucket, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+	bucket := newTokenBucket(config)
+	bucket.now = clock.Now
+	bucket.last = clock.now
+	return bucket, clock
+}
+
+func TestTokenBucket_Burst(t *testing.T) {
+	bucket, _ := newTestBucket(RateLimitConfig{RequestsPerSecond: 2, Burst: 3})
+
+	for i := 0; i < 3; i++ {
+		if delay := bucket.reserve(); delay != 0 {
+			t.Fatalf("Request %d within burst should not wait, got %v", i+1, delay)
+		}
+	}
+
+	if delay := bucket.reserve(); delay != 500*time.Millisecond {
+		t.Errorf("Expected 4th request to wait 500ms, got %v", delay)
+	}
+	if delay := bucket.reserve(); delay != time.Second {
+		t.Errorf("Expected 5th request to queue behind the 4th (1s), got %v", delay)
+	}
+}
+
+func TestTokenBucket_Refill(t *testing.T) {
+	bucket, clock := newTestBucket(RateLimitConfig{RequestsPerSecond: 10, Burst: 1})
+
+	bucket.reserve()
+	clock.now = clock.now.Add(100 * time.Millisecond)
+
+	if delay := bucket.reserve(); delay != 0 {
+		t.Errorf("Expected refilled token after 100ms, got wait %v", delay)
+	}
+
+	// Idle time never refills beyond the burst
+	clock.now = clock.now.Add(time.Hour)
+	bucket.reserve()
+	if delay := bucket.reserve(); delay == 0 {
+		t.Error("Expected bucket to be capped at its burst size")
+	}
+}
+
+func TestTokenBucket_Disabled(t *testing.T) {
+	bucket := newTokenBucket(RateLimitConfig{RequestsPerSecond: 0})
+	if bucket != nil {
+		t.Fatal("Expected nil bucket when rate limiting is disabled")
+	}
+	if !bucket.wait(nil) {
+		t.Error("Nil bucket should never block")
+	}
+}
+
+func TestTokenBucket_WaitAbortedOnStop(t *testing.T) {
+	bucket := newTokenBucket(RateLimitConfig{RequestsPerSecond: 0.01, Burst: 1})
+	bucket.reserve()
+
+	stop := make(chan struct{})
+	close(stop)
+
+	if bucket.wait(stop) {
+		t.Error("Expected wait to be aborted by stop")
+	}
+}
+
+func TestPoller_RateLimitThrottlesRequests(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(feedPageJSON(nil, "")))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	apps := make([]models.App, 5)
+	for i := range apps {
+		apps[i] = models.App{ID: fmt.Sprintf("app%d", i+1)}
+	}
+
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), apps, time.Second,
+		WithRateLimit(RateLimitConfig{RequestsPerSecond: 20, Burst: 1}))
+
+	start := time.Now()
+	poller.pollAllAppsConcurrently()
+	elapsed := time.Since(start)
+
+	if requests.Load() != 5 {
+		t.Errorf("Expected 5 requests, got %d", requests.Load())
+	}
+	// One request immediately, the other 4 spaced 50ms apart
+	if elapsed < 200*time.Millisecond {
+		t.Errorf("Expected requests to be throttled to 20/s, took only %v", elapsed)
+	}
+}
+
+func TestPoller_MaxConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		w.Write([]byte(feedPageJSON(nil, "")))
+	}))
+	defer server.Close()
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	apps := make([]models.App, 12)
+	for i := range apps {
+		apps[i] = models.App{ID: fmt.Sprintf("app%d", i+1)}
+	}
+
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), apps, time.Second,
+		WithMaxConcurrency(3), WithRateLimit(RateLimitConfig{}))
+
+	poller.pollAllAppsConcurrently()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if peak > 3 {
+		t.Errorf("Expected at most 3 concurrent polls, got %d", peak)
+	}
+	if peak < 2 {
+		t.Errorf("Expected apps to be polled concurrently, peak was %d", peak)
+	}
+}
+
+func TestNewPoller_DefaultConcurrencyAndRateLimit(t *testing.T) {
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), []models.App{}, time.Second)
+
+	if cap(poller.workers) != DefaultMaxConcurrency {
+		t.Errorf("Expected %d workers, got %d", DefaultMaxConcurrency, cap(poller.workers))
+	}
+	if poller.limiter == nil {
+		t.Fatal("Expected default rate limiter")
+	}
+	if poller.limiter.rate != DefaultRateLimitConfig().RequestsPerSecond {
+		t.Errorf("Expected rate %v, got %v", DefaultRateLimitConfig().RequestsPerSecond, poller.limiter.rate)
+	}
+}
@@ -0,0 +1,166 @@
+package poller
+
+import (
+	"crypto/rsa"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/internal/httpclient"
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+// recordingClient returns an HTTP client that records its responses to dir
+func recordingClient(dir string) *http.Client {
+	client := &http.Client{}
+	httpclient.Record(client, dir, log.New(io.Discard, "", 0))
+	return client
+}
+
+// replayClient returns an HTTP client that replays the recordings in dir
+func replayClient(t *testing.T, dir string) *http.Client {
+	t.Helper()
+	client := &http.Client{}
+	if err := httpclient.Replay(client, dir, log.New(io.Discard, "", 0)); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	return client
+}
+
+func TestPoller_RecordAndReplay(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if requests.Add(1) == 1 {
+			w.Write([]byte(feedPageJSON([]string{"r1", "r2"}, "")))
+			return
+		}
+		w.Write([]byte(feedPageJSON([]string{"r3", "r1", "r2"}, "")))
+	}))
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	dir := t.TempDir()
+	app := models.App{ID: "123"}
+	recorder := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), nil, time.Hour, WithHTTPClient(recordingClient(dir)))
+	for range 2 {
+		if _, err := recorder.fetchAndStore(app, "us"); err != nil {
+			t.Fatalf("fetchAndStore failed: %v", err)
+		}
+	}
+	server.Close()
+
+	recordings, err := httpclient.LoadRecordings(dir)
+	if err != nil {
+		t.Fatalf("LoadRecordings failed: %v", err)
+	}
+	if len(recordings) != 2 || recordings[0].Sequence != "123 (us)" || recordings[0].Run == recordings[1].Run {
+		t.Fatalf("Expected 2 recordings of separate polls of the feed, got %+v", recordings)
+	}
+
+	// Replay works without the server and serves the recorded polls in order
+	storage := testutil.NewMockStorage()
+	replayer := NewPoller(storage, log.New(io.Discard, "", 0), nil, time.Hour, WithHTTPClient(replayClient(t, dir)))
+	for i, expected := range []int{2, 1, 0} {
+		result, err := replayer.fetchAndStore(app, "us")
+		if err != nil {
+			t.Fatalf("Replayed fetch %d failed: %v", i+1, err)
+		}
+		if result.New != expected {
+			t.Errorf("Replayed fetch %d: expected %d new reviews, got %+v", i+1, expected, result)
+		}
+	}
+	if storage.GetSavedReviewCount() != 3 {
+		t.Errorf("Expected the 3 recorded reviews to be stored, got %d", storage.GetSavedReviewCount())
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected replay not to reach the network, got %d requests", requests.Load())
+	}
+}
+
+func TestPoller_ReplayKeepsPagesOfAPollTogether(t *testing.T) {
+	var polls atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page2 := server.URL + "/us/rss/customerreviews/id=123/sortBy=mostRecent/page=2/json"
+		switch {
+		case strings.Contains(r.URL.Path, "page=1") && polls.Add(1) == 1:
+			w.Write([]byte(feedPageJSON([]string{"r2", "r1"}, page2)))
+		case strings.Contains(r.URL.Path, "page=1"):
+			w.Write([]byte(feedPageJSON([]string{"r4", "r3"}, page2)))
+		default:
+			w.Write([]byte(feedPageJSON([]string{"r2", "r1"}, "")))
+		}
+	}))
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = server.URL
+	defer func() { feedBaseURL = originalBaseURL }()
+
+	// The first poll stops at the stored r1, only the second one fetches page 2
+	dir := t.TempDir()
+	app := models.App{ID: "123"}
+	seeded := testutil.NewMockStorage()
+	seeded.SaveReviews([]models.Review{{ID: "r1", AppID: "123", Country: "us"}})
+	recorder := NewPoller(seeded, log.New(io.Discard, "", 0), nil, time.Hour, WithHTTPClient(recordingClient(dir)))
+	for range 2 {
+		if _, err := recorder.fetchAndStore(app, "us"); err != nil {
+			t.Fatalf("fetchAndStore failed: %v", err)
+		}
+	}
+	server.Close()
+
+	// Replayed into empty storage the first poll walks on, but must not get
+	// the second poll's page 2
+	storage := testutil.NewMockStorage()
+	replayer := NewPoller(storage, log.New(io.Discard, "", 0), nil, time.Hour, WithHTTPClient(replayClient(t, dir)))
+	_, err := replayer.fetchAndStore(app, "us")
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected a 404 for the page the first poll did not record, got %v", err)
+	}
+
+	result, err := replayer.fetchAndStore(app, "us")
+	if err != nil {
+		t.Fatalf("Replayed second poll failed: %v", err)
+	}
+	if result.New != 2 || result.Fetched != 4 {
+		t.Errorf("Expected the second poll to replay both of its pages, got %+v", result)
+	}
+}
+
+func TestPoller_ReplayGooglePlay(t *testing.T) {
+	var publicKey rsa.PublicKey
+	server := newFakeGooglePlay(t, &publicKey, []string{"g1", "g2", "g3"}, 2)
+	keyFile, key := writeServiceAccountKey(t, server.URL+"/token")
+	publicKey = *key
+	googlePlay := WithGooglePlay(GooglePlayConfig{ServiceAccountFile: keyFile, BaseURL: server.URL})
+	app := models.App{ID: "com.example.app", Platform: models.PlatformAndroid}
+
+	dir := t.TempDir()
+	recorder := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), nil, time.Hour, WithHTTPClient(recordingClient(dir)), googlePlay)
+	if _, err := recorder.fetchAndStore(app, ""); err != nil {
+		t.Fatalf("fetchAndStore failed: %v", err)
+	}
+	server.Close()
+
+	// The token request was not recorded, replay makes one up
+	storage := testutil.NewMockStorage()
+	replayer := NewPoller(storage, log.New(io.Discard, "", 0), nil, time.Hour, WithHTTPClient(replayClient(t, dir)), googlePlay)
+	result, err := replayer.fetchAndStore(app, "")
+	if err != nil {
+		t.Fatalf("Replayed fetch failed: %v", err)
+	}
+	if result.New != 3 {
+		t.Errorf("Expected the 3 recorded reviews to be replayed, got %+v", result)
+	}
+}
@@ -0,0 +1,93 @@
+package poller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"backend/internal/httpclient"
+	"backend/internal/models"
+)
+
+// recordingParser is implemented by sources whose recorded responses can be
+// parsed again, see Reparse
+type recordingParser interface {
+	// parseRecording returns the reviews of a recorded response, or false if
+	// the response is not from one of the source's feeds
+	parseRecording(recording httpclient.Recording, stats *ParseStats) ([]models.Review, bool, error)
+}
+
+// ReparseResult summarizes a Reparse
+type ReparseResult struct {
+	Parsed  int // Recorded feed pages that were parsed
+	Skipped int // Other recordings, e.g. failed requests or metadata lookups
+	Reviews int // Distinct reviews found in the pages
+	Added   int // Found reviews that were not stored before
+	Parse   ParseStats
+}
+
+// Reparse parses the recorded feed pages again, oldest first, and stores
+// their reviews, e.g. to rebuild storage after a parser fix. Unlike a replay
+// it parses every recorded page, no matter which reviews are stored, and the
+// reviews keep the time they were recorded at as their fetch time. A page
+// that cannot be decoded does not stop the others; the failures are returned
+// together. It does not need the poller to be started.
+func (p *Poller) Reparse(recordings []httpclient.Recording) (ReparseResult, error) {
+	var result ReparseResult
+	var reviews []models.Review
+	var errs []error
+	seen := make(map[string]bool)
+
+	for _, recording := range recordings {
+		if recording.Status != http.StatusOK {
+			result.Skipped++
+			continue
+		}
+
+		parsed, ok, err := p.parseRecording(recording, &result.Parse)
+		if !ok {
+			result.Skipped++
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s recorded at %s: %w", recording.URL, recording.RecordedAt.Format(time.RFC3339), err))
+			continue
+		}
+		result.Parsed++
+
+		for _, review := range parsed {
+			if seen[review.ID] {
+				continue // Found in an earlier page too
+			}
+			seen[review.ID] = true
+			result.Reviews++
+			if !p.storage.HasReview(review.ID) {
+				result.Added++
+			}
+		}
+		// Later versions of a review are kept too, so its edits are recorded
+		reviews = append(reviews, parsed...)
+	}
+
+	if len(reviews) > 0 {
+		if err := p.storage.SaveReviews(reviews); err != nil {
+			return result, errors.Join(append(errs, fmt.Errorf("failed to store reviews: %w", err))...)
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// parseRecording parses a recorded response with the source it came from
+func (p *Poller) parseRecording(recording httpclient.Recording, stats *ParseStats) ([]models.Review, bool, error) {
+	for _, source := range p.sources {
+		parser, ok := source.(recordingParser)
+		if !ok {
+			continue
+		}
+		if reviews, ok, err := parser.parseRecording(recording, stats); ok {
+			return reviews, true, err
+		}
+	}
+	return nil, false, nil
+}
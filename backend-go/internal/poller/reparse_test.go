@@ -0,0 +1,77 @@
+package poller
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/internal/httpclient"
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+func TestPoller_Reparse(t *testing.T) {
+	keyFile, _ := writeServiceAccountKey(t, "https://oauth2.example/token")
+	googlePlay := WithGooglePlay(GooglePlayConfig{ServiceAccountFile: keyFile})
+
+	page1 := "https://itunes.apple.com/us/rss/customerreviews/id=123/sortBy=mostRecent/page=1/json"
+	page2 := "https://itunes.apple.com/us/rss/customerreviews/page=2/id=123/sortby=mostrecent/json"
+	recordedAt := time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC)
+	recordings := []httpclient.Recording{
+		{URL: page1, Status: http.StatusOK, Body: feedPageJSON([]string{"r2", "r1"}, page2), RecordedAt: recordedAt},
+		{URL: page2, Status: http.StatusOK, Body: feedPageJSON([]string{"r1", "r0"}, ""), RecordedAt: recordedAt},
+		{URL: page1, Status: http.StatusServiceUnavailable, RecordedAt: recordedAt.Add(time.Hour)},
+		{URL: "https://itunes.apple.com/lookup?id=123&country=us", Status: http.StatusOK, Body: `{"resultCount": 0}`},
+		{URL: "https://itunes.apple.com/de/rss/customerreviews/id=123/sortBy=mostRecent/page=1/json", Status: http.StatusOK, Body: "{"},
+		{
+			URL:    "https://androidpublisher.googleapis.com/androidpublisher/v3/applications/com.example.app/reviews?maxResults=100",
+			Status: http.StatusOK,
+			Body: `{"reviews": [{"reviewId": "g1", "authorName": "User", "comments": [
+				{"userComment": {"text": "Nice", "starRating": 5, "lastModified": {"seconds": "1700000000"}}}
+			]}]}`,
+			RecordedAt: recordedAt,
+		},
+	}
+
+	storage := testutil.NewMockStorage()
+	storage.SaveReviews([]models.Review{{ID: "r0", AppID: "123", Country: "us"}})
+	poller := NewPoller(storage, log.New(io.Discard, "", 0), nil, time.Hour, googlePlay)
+
+	result, err := poller.Reparse(recordings)
+	var decodeErr *decodeError
+	if !errors.As(err, &decodeErr) {
+		t.Errorf("Expected the undecodable page to be reported, got %v", err)
+	}
+
+	if result.Parsed != 3 || result.Skipped != 2 || result.Reviews != 4 || result.Added != 3 {
+		t.Errorf("Expected 3 parsed pages, 2 skipped recordings and 4 reviews of which 3 new, got %+v", result)
+	}
+	if review, ok := storage.GetReview("r2"); !ok || review.Country != "us" || !review.FetchedAt.Equal(recordedAt) {
+		t.Errorf("Expected r2 to be stored as fetched when it was recorded, got %+v", review)
+	}
+	if review, ok := storage.GetReview("g1"); !ok || review.AppID != "com.example.app" || review.Platform != models.PlatformAndroid {
+		t.Errorf("Expected the Google Play review to be stored, got %+v", review)
+	}
+}
+
+func TestITunesFeedOf(t *testing.T) {
+	tests := []struct {
+		url            string
+		appID, country string
+		ok             bool
+	}{
+		{"https://itunes.apple.com/us/rss/customerreviews/id=123/sortBy=mostRecent/page=1/json", "123", "us", true},
+		{"https://itunes.apple.com/gb/rss/customerreviews/page=2/id=456/sortby=mostrecent/json?urlDesc=x", "456", "gb", true},
+		{"https://itunes.apple.com/lookup?id=123", "", "", false},
+		{"https://itunes.apple.com/us/rss/topfreeapplications/json", "", "", false},
+	}
+	for _, tt := range tests {
+		appID, country, ok := iTunesFeedOf(tt.url)
+		if appID != tt.appID || country != tt.country || ok != tt.ok {
+			t.Errorf("iTunesFeedOf(%q) = %q, %q, %v, expected %q, %q, %v", tt.url, appID, country, ok, tt.appID, tt.country, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package poller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryConfig controls how failed feed requests are retried
+type RetryConfig struct {
+	MaxRetries int           // Retries after the first attempt (0 disables retrying)
+	BaseDelay  time.Duration // Backoff ceiling for the first retry, doubled on every attempt
+	MaxDelay   time.Duration // Upper bound for a single backoff
+}
+
+// DefaultRetryConfig returns the retry settings used when none are configured
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxRetries: 3,
+		BaseDelay:  time.Second,
+		MaxDelay:   30 * time.Second,
+	}
+}
+
+// backoff returns a random delay between 0 and the exponential ceiling for
+// the given retry attempt (full jitter)
+func (c RetryConfig) backoff(attempt int) time.Duration {
+	ceiling := c.MaxDelay
+	if attempt < 62 {
+		if exp := c.BaseDelay << attempt; exp > 0 && exp < ceiling {
+			ceiling = exp
+		}
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling + 1)
+}
+
+// StatusError is returned when the feed responds with an unexpected HTTP status
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // Delay requested by the server via Retry-After, if any
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// retryable reports whether the request may succeed if repeated. Only 5xx
+// and 429 are worth retrying; other 4xx responses (bad request, forbidden,
+// not found) will not change on their own.
+func (e *StatusError) retryable() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+// newStatusError builds a StatusError from a non-200 response
+func newStatusError(resp *http.Response) *StatusError {
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// get performs a conditional GET request, retrying network errors, 5xx and 429 responses
+// with exponential backoff. Every attempt waits for the shared rate limiter.
+// ctx has to be derived from the poller's, so Stop aborts the request.
+// The caller must close the response body.
+func (p *Poller) get(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("User-Agent", p.userAgent)
+	p.validators.apply(url, req)
+
+	for attempt := 0; ; attempt++ {
+		if !p.limiter.wait(ctx.Done()) {
+			return nil, fmt.Errorf("%w: rate limit wait aborted", ErrCancelled)
+		}
+
+		resp, err := p.do(req)
+		if err == nil {
+			if attempt > 0 {
+				p.logger.Printf("Fetched %s after %d retries", url, attempt)
+			}
+			return resp, nil
+		}
+		if errors.Is(err, ErrNotModified) || errors.Is(err, ErrCancelled) {
+			return nil, err
+		}
+
+		var statusErr *StatusError
+		isStatusErr := errors.As(err, &statusErr)
+		if isStatusErr && !statusErr.retryable() {
+			return nil, err
+		}
+
+		if attempt >= p.retry.MaxRetries {
+			if attempt > 0 {
+				return nil, fmt.Errorf("%w (gave up after %d retries)", err, attempt)
+			}
+			return nil, err
+		}
+
+		delay := p.retry.backoff(attempt)
+		if isStatusErr && statusErr.RetryAfter > 0 {
+			if statusErr.RetryAfter > p.retry.MaxDelay {
+				// Waiting that long would stall the poll, try again next cycle instead
+				return nil, fmt.Errorf("%w (server asked to retry after %v)", err, statusErr.RetryAfter)
+			}
+			delay = max(delay, statusErr.RetryAfter)
+		}
+
+		p.logger.Printf("Retrying %s in %v (retry %d/%d): %v", url, delay, attempt+1, p.retry.MaxRetries, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%w: retry aborted after %v", ErrCancelled, err)
+		}
+	}
+}
+
+// do sends a single request and turns non-200 responses into a StatusError,
+// or ErrNotModified for a 304
+func (p *Poller) do(req *http.Request) (*http.Response, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if req.Context().Err() != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCancelled, err)
+		}
+		return nil, fmt.Errorf("failed to fetch reviews: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		return nil, ErrNotModified
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, newStatusError(resp)
+	}
+
+	return resp, nil
+}
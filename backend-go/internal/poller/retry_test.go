@@ -0,0 +1,270 @@
+package poller
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+// fastRetry keeps retrying behaviour but without real waiting in tests
+var fastRetry = RetryConfig{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+func TestRetryConfig_BackoffBounds(t *testing.T) {
+	config := RetryConfig{MaxRetries: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt := 0; attempt < 10; attempt++ {
+		ceiling := min(config.BaseDelay<<attempt, config.MaxDelay)
+		for range 50 {
+			delay := config.backoff(attempt)
+			if delay < 0 || delay > ceiling {
+				t.Fatalf("Attempt %d: expected delay within [0, %v], got %v", attempt, ceiling, delay)
+			}
+		}
+	}
+}
+
+func TestRetryConfig_BackoffLargeAttempt(t *testing.T) {
+	config := RetryConfig{MaxRetries: 100, BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+
+	// Shifting past the width of time.Duration must not overflow into negative delays
+	if delay := config.backoff(80); delay < 0 || delay > config.MaxDelay {
+		t.Errorf("Expected delay within [0, %v], got %v", config.MaxDelay, delay)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{"-1", 0},
+		{"soon", 0},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second},
+		{now.Add(-10 * time.Second).Format(http.TimeFormat), 0},
+	}
+
+	for _, test := range tests {
+		if got := parseRetryAfter(test.value, now); got != test.expected {
+			t.Errorf("parseRetryAfter(%q): expected %v, got %v", test.value, test.expected, got)
+		}
+	}
+}
+
+func TestPoller_RetriesServerErrors(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(feedPageJSON([]string{"r1"}, "")))
+	}))
+	defer server.Close()
+
+	var buf testutil.SafeBuffer
+	poller := NewPoller(testutil.NewMockStorage(), log.New(&buf, "", 0), []models.App{}, time.Second, WithRetry(fastRetry))
+
+	reviews, _, err := fetchFeedPage(poller, server.URL, "123", "us")
+	if err != nil {
+		t.Fatalf("fetchReviews failed: %v", err)
+	}
+	if len(reviews) != 1 {
+		t.Errorf("Expected 1 review, got %d", len(reviews))
+	}
+	if requests.Load() != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests.Load())
+	}
+
+	logOutput := buf.String()
+	if strings.Count(logOutput, "Retrying ") != 2 {
+		t.Errorf("Expected 2 retry log lines, got: %s", logOutput)
+	}
+	if !strings.Contains(logOutput, "after 2 retries") {
+		t.Errorf("Expected retry count in log, got: %s", logOutput)
+	}
+}
+
+func TestPoller_RetriesTooManyRequests(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(feedPageJSON(nil, "")))
+	}))
+	defer server.Close()
+
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), []models.App{}, time.Second, WithRetry(fastRetry))
+
+	if _, _, err := fetchFeedPage(poller, server.URL, "123", "us"); err != nil {
+		t.Fatalf("fetchReviews failed: %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests.Load())
+	}
+}
+
+func TestPoller_DoesNotRetryClientErrors(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound} {
+		var requests atomic.Int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(status)
+		}))
+
+		poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), []models.App{}, time.Second, WithRetry(fastRetry))
+
+		_, _, err := fetchFeedPage(poller, server.URL, "123", "us")
+		server.Close()
+
+		if err == nil {
+			t.Fatalf("Expected error for status %d, got nil", status)
+		}
+		if requests.Load() != 1 {
+			t.Errorf("Status %d: expected 1 request, got %d", status, requests.Load())
+		}
+	}
+}
+
+func TestPoller_DoesNotRetryDecodeErrors(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte("invalid json"))
+	}))
+	defer server.Close()
+
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), []models.App{}, time.Second, WithRetry(fastRetry))
+
+	if _, _, err := fetchFeedPage(poller, server.URL, "123", "us"); err == nil {
+		t.Fatal("Expected decode error, got nil")
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 request, got %d", requests.Load())
+	}
+}
+
+func TestPoller_RetriesGiveUp(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), []models.App{}, time.Second, WithRetry(fastRetry))
+
+	_, _, err := fetchFeedPage(poller, server.URL, "123", "us")
+	if err == nil {
+		t.Fatal("Expected error after exhausting retries, got nil")
+	}
+	if !strings.Contains(err.Error(), "gave up after 3 retries") {
+		t.Errorf("Expected retry count in error, got: %v", err)
+	}
+	if requests.Load() != 4 {
+		t.Errorf("Expected 4 requests (1 + 3 retries), got %d", requests.Load())
+	}
+}
+
+func TestPoller_HonoursRetryAfter(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(feedPageJSON(nil, "")))
+	}))
+	defer server.Close()
+
+	retry := RetryConfig{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), []models.App{}, time.Second, WithRetry(retry))
+
+	start := time.Now()
+	if _, _, err := fetchFeedPage(poller, server.URL, "123", "us"); err != nil {
+		t.Fatalf("fetchReviews failed: %v", err)
+	}
+	if waited := time.Since(start); waited < time.Second {
+		t.Errorf("Expected retry to wait for Retry-After (1s), waited %v", waited)
+	}
+}
+
+func TestPoller_RetryAfterBeyondMaxDelayGivesUp(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), []models.App{}, time.Second, WithRetry(fastRetry))
+
+	_, _, err := fetchFeedPage(poller, server.URL, "123", "us")
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected no retry for a Retry-After beyond the max delay, got %d requests", requests.Load())
+	}
+}
+
+func TestPoller_RetryAbortedOnStop(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	retry := RetryConfig{MaxRetries: 5, BaseDelay: time.Minute, MaxDelay: time.Minute}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), []models.App{}, time.Second, WithRetry(retry))
+
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := fetchFeedPage(poller, server.URL, "123", "us")
+		done <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	poller.cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrCancelled) || !strings.Contains(err.Error(), "retry aborted") {
+			t.Errorf("Expected aborted retry error, got: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Retry backoff was not interrupted by stop")
+	}
+}
+
+func TestNewPoller_DefaultRetryConfig(t *testing.T) {
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), []models.App{}, time.Second)
+
+	if poller.retry != DefaultRetryConfig() {
+		t.Errorf("Expected default retry config %+v, got %+v", DefaultRetryConfig(), poller.retry)
+	}
+}
@@ -1,28 +1,134 @@
 package poller
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+)
+
 // RSSFeed represents the App Store RSS feed structure
 type RSSFeed struct {
-	Feed struct {
-		Entry []RSSEntry `json:"entry"`
-	} `json:"feed"`
+	Feed RSSFeedBody `json:"feed"`
+}
+
+type RSSFeedBody struct {
+	Entry RSSEntries `json:"entry"`
+	Link  RSSLinks   `json:"link"`
+}
+
+// RSSEntries are the entries of a feed page. iTunes sends a single entry as
+// an object instead of an array, and one malformed entry must not fail the
+// whole page, so each entry is decoded on its own.
+type RSSEntries []RSSEntry
+
+func (e *RSSEntries) UnmarshalJSON(data []byte) error {
+	elements, err := oneOrMany(data)
+	if err != nil {
+		return fmt.Errorf("feed entry: %w", err)
+	}
+
+	entries := make(RSSEntries, len(elements))
+	for i, element := range elements {
+		if err := json.Unmarshal(element, &entries[i]); err != nil {
+			entries[i] = RSSEntry{decodeErr: err}
+		}
+		// Remember which fields the entry had to notice when the feed changes shape
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(element, &fields) == nil {
+			entries[i].fields = slices.Collect(maps.Keys(fields))
+		}
+	}
+	*e = entries
+	return nil
+}
+
+// RSSLinks are the navigation links of a feed page, sent as an object when
+// there is only one
+type RSSLinks []RSSLink
+
+func (l *RSSLinks) UnmarshalJSON(data []byte) error {
+	elements, err := oneOrMany(data)
+	if err != nil {
+		return fmt.Errorf("feed link: %w", err)
+	}
+
+	links := make(RSSLinks, 0, len(elements))
+	for _, element := range elements {
+		var link RSSLink
+		if err := json.Unmarshal(element, &link); err != nil {
+			continue // Navigation is best effort, a broken link just ends the walk
+		}
+		links = append(links, link)
+	}
+	*l = links
+	return nil
+}
+
+// oneOrMany splits a JSON array into its elements, or wraps a single object.
+// null gives no elements.
+func oneOrMany(data []byte) ([]json.RawMessage, error) {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		return nil, nil
+	case bytes.HasPrefix(data, []byte("[")):
+		var elements []json.RawMessage
+		if err := json.Unmarshal(data, &elements); err != nil {
+			return nil, err
+		}
+		return elements, nil
+	case bytes.HasPrefix(data, []byte("{")):
+		return []json.RawMessage{data}, nil
+	default:
+		return nil, fmt.Errorf("expected an object or an array, got %.20s", data)
+	}
+}
+
+// RSSLink is a feed navigation link (rel is "self", "first", "next", "last", ...)
+type RSSLink struct {
+	Attributes struct {
+		Rel  string `json:"rel"`
+		Href string `json:"href"`
+	} `json:"attributes"`
 }
 
 type RSSEntry struct {
+	AppName struct {
+		Label string `json:"label"` // Only set in the app metadata entry some storefronts send first
+	} `json:"im:name"`
 	Author struct {
 		Name struct {
 			Label string `json:"label"`
 		} `json:"name"`
 	} `json:"author"`
+	Title struct {
+		Label string `json:"label"`
+	} `json:"title"`
 	Content struct {
 		Label string `json:"label"`
 	} `json:"content"`
 	Rating struct {
 		Label string `json:"label"` // "1" to "5"
 	} `json:"im:rating"`
+	Version struct {
+		Label string `json:"label"` // App version, e.g. "4.2.0"
+	} `json:"im:version"`
+	VoteSum struct {
+		Label string `json:"label"` // Integer as string
+	} `json:"im:voteSum"`
+	VoteCount struct {
+		Label string `json:"label"` // Integer as string
+	} `json:"im:voteCount"`
+	Link    RSSLink `json:"link"`
 	Updated struct {
 		Label string `json:"label"` // ISO 8601 timestamp
 	} `json:"updated"`
 	ID struct {
 		Label string `json:"label"`
 	} `json:"id"`
+
+	decodeErr error    // Set by RSSEntries when the entry could not be decoded
+	fields    []string // Top-level fields of the entry, set by RSSEntries
 }
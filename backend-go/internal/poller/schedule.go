@@ -0,0 +1,191 @@
+package poller
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"backend/internal/models"
+)
+
+// DefaultJitter is the fraction of an app's interval by which its polls are randomly shifted
+const DefaultJitter = 0.1
+
+// maxStartDelay caps how long the first poll of an app added while the
+// poller runs is delayed
+const maxStartDelay = 30 * time.Second
+
+// idleWait is how long the scheduler sleeps when no app is waiting to be polled
+const idleWait = time.Hour
+
+// WithJitter sets the fraction of an app's interval by which polls are randomly
+// shifted, so apps sharing an interval do not all hit the store at once
+func WithJitter(fraction float64) Option {
+	return func(p *Poller) {
+		p.jitter = min(max(fraction, 0), 1)
+	}
+}
+
+// scheduledApp is the scheduler's view of one app
+type scheduledApp struct {
+	app     models.App
+	base    time.Duration // Configured interval the app is scheduled with
+	due     time.Time     // When the next poll starts
+	started time.Time     // When the running poll started
+	running bool
+}
+
+// run is the scheduler loop. All apps are polled right away, after that
+// every app is polled on its own interval; polls of apps that are due run
+// concurrently on the worker pool, and an app is only rescheduled once its
+// running poll has finished.
+func (p *Poller) run() {
+	defer p.wg.Done()
+
+	start := time.Now()
+	schedule := p.reschedule(nil, start)
+	p.logger.Printf("Scheduler started for %d apps", len(schedule))
+
+	// The first poll after Start is immediate, jitter only shifts later ones
+	p.pollAllAppsConcurrently()
+	for _, s := range schedule {
+		s.started = start
+		s.due = start.Add(p.nextDelay(s.app))
+	}
+
+	finished := make(chan *scheduledApp)
+	timer := time.NewTimer(idleWait)
+	defer timer.Stop()
+
+	for {
+		now := time.Now()
+		var next time.Time
+
+		for _, s := range schedule {
+			if s.running {
+				continue
+			}
+			if !s.due.After(now) {
+				s.running = true
+				s.started = now
+				p.wg.Add(1)
+				go p.pollScheduled(s, s.app, finished)
+				continue
+			}
+			if next.IsZero() || s.due.Before(next) {
+				next = s.due
+			}
+		}
+
+		wait := idleWait
+		if !next.IsZero() {
+			wait = time.Until(next)
+		}
+		timer.Reset(wait)
+
+		select {
+		case s := <-finished:
+			s.running = false
+			s.due = s.started.Add(p.nextDelay(s.app))
+		case <-p.appsChanged:
+			schedule = p.reschedule(schedule, time.Now())
+		case <-timer.C:
+		case <-p.stopChan:
+			return
+		}
+	}
+}
+
+// reschedule brings the schedule in line with the current apps. Apps keep
+// their place in the schedule; apps that are new get a spread first poll,
+// which run overrides for the apps polled on Start.
+func (p *Poller) reschedule(schedule []*scheduledApp, now time.Time) []*scheduledApp {
+	scheduled := make(map[string]*scheduledApp, len(schedule))
+	for _, s := range schedule {
+		scheduled[s.app.ID] = s
+	}
+
+	apps := p.Apps()
+	next := make([]*scheduledApp, 0, len(apps))
+	for _, app := range apps {
+		s, ok := scheduled[app.ID]
+		if !ok {
+			// Spread the first polls so apps do not all start in the same second
+			next = append(next, &scheduledApp{app: app, base: p.baseInterval(app), due: now.Add(p.startDelay(app))})
+			continue
+		}
+		if base := p.baseInterval(app); base != s.base {
+			s.base = base
+			p.resetAdaptive(app.ID)
+			if !s.running && !s.started.IsZero() {
+				s.due = s.started.Add(p.nextDelay(app))
+			}
+		}
+		s.app = app
+		next = append(next, s)
+	}
+	return next
+}
+
+// pollScheduled polls an app on a worker and reports back to the scheduler.
+// The app is passed separately since the scheduler may update s meanwhile.
+func (p *Poller) pollScheduled(s *scheduledApp, app models.App, finished chan<- *scheduledApp) {
+	defer p.wg.Done()
+
+	start := time.Now()
+	if result := p.pollMerged(app); result.Cancelled {
+		return
+	}
+	p.logger.Printf("Polled app %s in %v", app.ID, time.Since(start))
+
+	select {
+	case finished <- s:
+	case <-p.stopChan:
+	}
+}
+
+// baseInterval returns the configured poll interval of an app, falling back to the poller default
+func (p *Poller) baseInterval(app models.App) time.Duration {
+	if app.Interval > 0 {
+		return time.Duration(app.Interval)
+	}
+
+	p.appsMu.RLock()
+	defer p.appsMu.RUnlock()
+	return p.pollInterval
+}
+
+// intervalFor returns the effective poll interval of an app, which follows
+// its review velocity when adaptive polling is enabled. It only reads the
+// adaptive interval, apps start at their base interval until a poll adapts it.
+func (p *Poller) intervalFor(app models.App) time.Duration {
+	if !p.adaptiveCfg.enabled() {
+		return p.baseInterval(app)
+	}
+
+	p.intervalsMu.Lock()
+	interval, ok := p.intervals[app.ID]
+	p.intervalsMu.Unlock()
+	if !ok {
+		return p.baseInterval(app)
+	}
+	return interval.interval()
+}
+
+// startDelay returns a random delay for the first poll of an app
+func (p *Poller) startDelay(app models.App) time.Duration {
+	spread := min(time.Duration(float64(p.baseInterval(app))*p.jitter), maxStartDelay)
+	if spread <= 0 {
+		return 0
+	}
+	return rand.N(spread)
+}
+
+// nextDelay returns the app's interval shifted randomly by up to ±jitter
+func (p *Poller) nextDelay(app models.App) time.Duration {
+	interval := p.intervalFor(app)
+	spread := time.Duration(float64(interval) * p.jitter)
+	if spread <= 0 {
+		return interval
+	}
+	return interval - spread + rand.N(2*spread+1)
+}
@@ -0,0 +1,182 @@
+package poller
+
+import (
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+func TestPoller_PerAppIntervals(t *testing.T) {
+	var buf testutil.SafeBuffer
+	apps := []models.App{
+		{ID: "fast", Interval: models.Duration(50 * time.Millisecond)},
+		{ID: "slow", Interval: models.Duration(time.Hour)},
+		{ID: "default"},
+	}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(&buf, "", 0), apps, 150*time.Millisecond,
+		WithJitter(0))
+
+	poller.Start()
+	time.Sleep(320 * time.Millisecond)
+	poller.Stop()
+
+	logOutput := buf.String()
+	fast := strings.Count(logOutput, "Fetching reviews for app fast")
+	slow := strings.Count(logOutput, "Fetching reviews for app slow")
+	def := strings.Count(logOutput, "Fetching reviews for app default")
+
+	if fast < 5 {
+		t.Errorf("Expected the 50ms app to be polled at least 5 times, got %d", fast)
+	}
+	if slow != 1 {
+		t.Errorf("Expected the hourly app to be polled once, got %d", slow)
+	}
+	if def < 2 || def > 3 {
+		t.Errorf("Expected the app on the 150ms default interval to be polled 2-3 times, got %d", def)
+	}
+}
+
+func TestPoller_SlowPollDoesNotOverlap(t *testing.T) {
+	var buf testutil.SafeBuffer
+	apps := []models.App{{ID: "app1", Interval: models.Duration(10 * time.Millisecond)}}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(&buf, "", 0), apps, time.Second,
+		WithRetry(RetryConfig{MaxRetries: 0}),
+		// Each poll waits ~50ms for a token, longer than the interval
+		WithRateLimit(RateLimitConfig{RequestsPerSecond: 20, Burst: 1}))
+
+	poller.Start()
+	time.Sleep(175 * time.Millisecond)
+	poller.Stop()
+
+	// The app is only rescheduled once its poll finished, so polls are spaced by the rate limit
+	if count := strings.Count(buf.String(), "Fetching reviews for app app1"); count > 5 {
+		t.Errorf("Expected polls of the same app not to overlap, got %d polls", count)
+	}
+}
+
+func TestPoller_StartDelayIsSpread(t *testing.T) {
+	poller := NewPoller(testutil.NewMockStorage(), nil, nil, time.Hour, WithJitter(0.5))
+
+	seen := make(map[time.Duration]bool)
+	for i := 0; i < 50; i++ {
+		delay := poller.startDelay(models.App{ID: "app1"})
+		if delay < 0 || delay >= maxStartDelay {
+			t.Fatalf("Expected start delay within [0, %v), got %v", maxStartDelay, delay)
+		}
+		seen[delay] = true
+	}
+	if len(seen) < 2 {
+		t.Error("Expected start delays to be randomized")
+	}
+}
+
+func TestPoller_NextDelayJitter(t *testing.T) {
+	poller := NewPoller(testutil.NewMockStorage(), nil, nil, time.Minute, WithJitter(0.1))
+	app := models.App{ID: "app1", Interval: models.Duration(time.Hour)}
+
+	for i := 0; i < 100; i++ {
+		delay := poller.nextDelay(app)
+		if delay < 54*time.Minute || delay > 66*time.Minute {
+			t.Fatalf("Expected delay within 10%% of 1h, got %v", delay)
+		}
+	}
+
+	noJitter := NewPoller(testutil.NewMockStorage(), nil, nil, time.Minute, WithJitter(0))
+	if delay := noJitter.nextDelay(app); delay != time.Hour {
+		t.Errorf("Expected exact interval without jitter, got %v", delay)
+	}
+	if delay := noJitter.nextDelay(models.App{ID: "app2"}); delay != time.Minute {
+		t.Errorf("Expected the default interval for apps without their own, got %v", delay)
+	}
+}
+
+func TestPoller_SetAppsWhileRunning(t *testing.T) {
+	var buf testutil.SafeBuffer
+	apps := []models.App{
+		{ID: "removed", Interval: models.Duration(30 * time.Millisecond)},
+		{ID: "changed", Interval: models.Duration(time.Hour)},
+	}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(&buf, "", 0), apps, time.Hour, WithJitter(0))
+
+	poller.Start()
+	time.Sleep(50 * time.Millisecond)
+
+	poller.SetApps([]models.App{
+		{ID: "changed", Interval: models.Duration(30 * time.Millisecond)},
+		{ID: "added", Interval: models.Duration(30 * time.Millisecond)},
+	})
+	time.Sleep(20 * time.Millisecond) // Let a running poll of the removed app finish
+	removedBefore := strings.Count(buf.String(), "Fetching reviews for app removed")
+	time.Sleep(150 * time.Millisecond)
+	poller.Stop()
+
+	logOutput := buf.String()
+	if removed := strings.Count(logOutput, "Fetching reviews for app removed"); removed != removedBefore {
+		t.Errorf("Expected the removed app not to be polled anymore, went from %d to %d polls", removedBefore, removed)
+	}
+	if added := strings.Count(logOutput, "Fetching reviews for app added"); added < 3 {
+		t.Errorf("Expected the added app to be polled on its interval, got %d polls", added)
+	}
+	if changed := strings.Count(logOutput, "Fetching reviews for app changed"); changed < 3 {
+		t.Errorf("Expected the shortened interval to apply right away, got %d polls", changed)
+	}
+
+	if got := poller.Apps(); len(got) != 2 || got[0].ID != "changed" || got[1].ID != "added" {
+		t.Errorf("Expected the new apps, got %+v", got)
+	}
+}
+
+func TestPoller_SetAppsForgetsRemovedApps(t *testing.T) {
+	apps := []models.App{{ID: "123"}, {ID: "456"}}
+	poller := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), apps, time.Hour,
+		WithAdaptiveInterval(AdaptiveConfig{MaxFactor: 4, TargetNewReviews: 1}))
+	for _, app := range apps {
+		poller.breakerFor(app.ID, "us").recordFailure()
+		poller.adapt(app, 0)
+		poller.records.record(app.ID, time.Now(), pollResult{Err: errors.New("boom")})
+		poller.schemas.observe(app.ID, "us", ParseStats{Seen: 1}, time.Now())
+	}
+
+	poller.SetApps([]models.App{{ID: "456"}})
+
+	if _, ok := poller.breakers["123"]; ok {
+		t.Error("Expected the breakers of the removed app to be dropped")
+	}
+	if _, ok := poller.intervals["123"]; ok {
+		t.Error("Expected the adaptive interval of the removed app to be dropped")
+	}
+	if _, ok := poller.records.apps["123"]; ok {
+		t.Error("Expected the poll record of the removed app to be dropped")
+	}
+	if _, ok := poller.schemas.feeds["123"]; ok {
+		t.Error("Expected the schema state of the removed app to be dropped")
+	}
+
+	_, kept := poller.breakers["456"]
+	_, record := poller.records.apps["456"]
+	if !kept || !record {
+		t.Error("Expected the state of the remaining app to be kept")
+	}
+}
+
+func TestPoller_SetIntervalWhileRunning(t *testing.T) {
+	var buf testutil.SafeBuffer
+	poller := NewPoller(testutil.NewMockStorage(), log.New(&buf, "", 0), []models.App{{ID: "app1"}}, time.Hour,
+		WithJitter(0))
+
+	poller.Start()
+	time.Sleep(30 * time.Millisecond)
+	poller.SetInterval(30 * time.Millisecond)
+	time.Sleep(150 * time.Millisecond)
+	poller.Stop()
+
+	if count := strings.Count(buf.String(), "Fetching reviews for app app1"); count < 3 {
+		t.Errorf("Expected the shorter default interval to apply right away, got %d polls", count)
+	}
+}
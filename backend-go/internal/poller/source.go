@@ -0,0 +1,105 @@
+package poller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"sync"
+
+	"backend/internal/httpclient"
+	"backend/internal/models"
+)
+
+// ReviewSource fetches reviews from one app store. The poller drives a source
+// per platform and takes care of scheduling, storage and failure handling.
+type ReviewSource interface {
+	// Platform returns the models.Platform* value of the apps this source serves
+	Platform() string
+
+	// FetchReviews returns the newest reviews of req.App, walking back until
+	// req.Seen reports a review that is already stored. Reviews fetched before
+	// a failure are returned together with the error. ErrNotModified means the
+	// store reported no changes since the previous fetch.
+	FetchReviews(client HTTPClient, req FetchRequest) ([]models.Review, error)
+}
+
+// recentOnlySource is implemented by sources that only serve the reviews of
+// a recent window. Their feeds are legitimately empty while an app is quiet,
+// so an empty feed is no sign of schema drift.
+type recentOnlySource interface {
+	recentOnly() bool
+}
+
+// FetchRequest describes a single feed fetched by a ReviewSource
+type FetchRequest struct {
+	App     models.App
+	Country string               // Storefront country code, empty for stores without storefronts
+	Seen    func(id string) bool // Reports whether a review is already stored
+	Stats   *ParseStats          // Counts how the store's entries were parsed, may be nil
+}
+
+// HTTPClient is how review sources talk to their store. Requests share the
+// poller's rate limit; GETs are also retried and sent conditionally.
+type HTTPClient interface {
+	// Get performs a GET with the given extra headers. A non-200 response is
+	// returned as a *StatusError and a 304 as ErrNotModified. The caller must
+	// close the response body.
+	Get(url string, header http.Header) (*http.Response, error)
+
+	// PostForm posts a form once, without retries. Non-200 responses are
+	// returned as a *StatusError.
+	PostForm(url string, data neturl.Values) (*http.Response, error)
+}
+
+// sourceClient is the HTTPClient handed to a source for one fetch. It records
+// the validators of every successful GET and remembers those URLs, so the
+// poller can forget them again if the fetch fails. Its requests form one
+// httpclient sequence, so a replay serves the pages of a single recorded fetch.
+type sourceClient struct {
+	p        *Poller
+	ctx      context.Context
+	mu       sync.Mutex
+	recorded []string
+}
+
+func (p *Poller) newSourceClient(feed string) *sourceClient {
+	return &sourceClient{p: p, ctx: httpclient.WithSequence(p.ctx, feed)}
+}
+
+func (c *sourceClient) Get(url string, header http.Header) (*http.Response, error) {
+	resp, err := c.p.get(c.ctx, url, header)
+	if err != nil {
+		return nil, err
+	}
+
+	c.p.validators.record(url, resp.Header)
+	c.mu.Lock()
+	c.recorded = append(c.recorded, url)
+	c.mu.Unlock()
+
+	return resp, nil
+}
+
+func (c *sourceClient) PostForm(url string, data neturl.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(c.ctx, "POST", url, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", c.p.userAgent)
+
+	if !c.p.limiter.wait(c.ctx.Done()) {
+		return nil, fmt.Errorf("%w: rate limit wait aborted", ErrCancelled)
+	}
+	return c.p.do(req)
+}
+
+// forgetRecorded drops the validators recorded during this fetch
+func (c *sourceClient) forgetRecorded() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.p.validators.forget(c.recorded...)
+}
@@ -0,0 +1,143 @@
+package poller
+
+import (
+	"sync"
+	"time"
+
+	"backend/internal/models"
+)
+
+// AppStatus describes the polling state of a single app
+type AppStatus struct {
+	AppID               string          `json:"app_id"`
+	Interval            models.Duration `json:"interval"` // Effective poll interval, see WithAdaptiveInterval
+	LastAttempt         *time.Time      `json:"last_attempt,omitempty"`
+	LastSuccess         *time.Time      `json:"last_success,omitempty"` // Last poll in which a storefront was fetched
+	LastError           string          `json:"last_error,omitempty"`   // Error of the last poll, empty once a poll succeeded for every storefront
+	LastErrorAt         *time.Time      `json:"last_error_at,omitempty"`
+	ConsecutiveFailures int             `json:"consecutive_failures"`   // Polls in a row in which no storefront was fetched
+	Duration            models.Duration `json:"duration"`               // Duration of the last poll
+	ReviewsFetched      int             `json:"reviews_fetched"`        // Reviews returned by the last poll
+	ReviewsNew          int             `json:"reviews_new"`            // Reviews of the last poll that were not stored before
+	Parse               ParseStats      `json:"parse"`                  // How the feed entries of the last poll were parsed
+	SchemaDrift         []DriftStatus   `json:"schema_drift,omitempty"` // Feeds whose entries no longer look like they used to
+	Breakers            []FeedBreaker   `json:"breakers"`               // Circuit breaker of each storefront
+}
+
+// FeedBreaker is the circuit breaker status of one app storefront
+type FeedBreaker struct {
+	Country string `json:"country,omitempty"` // Empty for stores without storefronts
+	BreakerStatus
+}
+
+// Status returns the polling state of every configured app, in config order
+func (p *Poller) Status() []AppStatus {
+	apps := p.Apps()
+	statuses := make([]AppStatus, 0, len(apps))
+	for _, app := range apps {
+		record := p.records.get(app.ID)
+
+		var breakers []FeedBreaker
+		for _, country := range feedCountries(app) {
+			breakers = append(breakers, FeedBreaker{Country: country, BreakerStatus: p.breakerStatus(app.ID, country)})
+		}
+
+		statuses = append(statuses, AppStatus{
+			AppID:               app.ID,
+			Interval:            models.Duration(p.intervalFor(app)),
+			LastAttempt:         optionalTime(record.lastAttempt),
+			LastSuccess:         optionalTime(record.lastSuccess),
+			LastError:           record.lastError,
+			LastErrorAt:         optionalTime(record.lastErrorAt),
+			ConsecutiveFailures: record.failures,
+			Duration:            models.Duration(record.duration),
+			ReviewsFetched:      record.fetched,
+			ReviewsNew:          record.new,
+			Parse:               record.parse,
+			SchemaDrift:         p.schemas.drifts(app.ID),
+			Breakers:            breakers,
+		})
+	}
+	return statuses
+}
+
+// pollRecord is the outcome of the latest polls of an app
+type pollRecord struct {
+	lastAttempt time.Time
+	lastSuccess time.Time
+	lastError   string
+	lastErrorAt time.Time
+	failures    int // Consecutive polls in which no storefront was fetched
+	duration    time.Duration
+	fetched     int
+	new         int
+	parse       ParseStats
+}
+
+// pollRecords keeps the pollRecord of every polled app
+type pollRecords struct {
+	mu   sync.Mutex
+	apps map[string]*pollRecord
+}
+
+// forget drops the record of an app
+func (r *pollRecords) forget(appID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.apps, appID)
+}
+
+// record stores the result of a poll of an app that started at start
+func (r *pollRecords) record(appID string, start time.Time, result pollResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.apps[appID]
+	if !ok {
+		record = &pollRecord{}
+		r.apps[appID] = record
+	}
+
+	end := time.Now()
+	record.lastAttempt = start
+	record.duration = end.Sub(start)
+	record.fetched = result.Fetched
+	record.new = result.New
+	record.parse = result.Parse
+	if result.Succeeded {
+		record.lastSuccess = end
+	}
+	if result.Succeeded || result.Err == nil {
+		record.failures = 0
+	} else {
+		record.failures++
+	}
+	// Failed storefronts are reported even when others succeeded, and only
+	// until a poll fetched every storefront again
+	if result.Err != nil {
+		record.lastError = result.Err.Error()
+		record.lastErrorAt = end
+	} else {
+		record.lastError = ""
+		record.lastErrorAt = time.Time{}
+	}
+}
+
+// get returns a copy of the record of an app, empty if it was never polled
+func (r *pollRecords) get(appID string) pollRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if record, ok := r.apps[appID]; ok {
+		return *record
+	}
+	return pollRecord{}
+}
+
+// optionalTime returns nil for the zero time so it is omitted from JSON
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,136 @@
+package poller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	"backend/internal/storage"
+)
+
+// ErrNotModified is returned when a conditional request finds the feed unchanged
+var ErrNotModified = errors.New("feed not modified")
+
+// feedValidators are the HTTP cache validators last seen for a feed URL
+type feedValidators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// validatorStore remembers feed validators per URL so that unchanged feeds
+// can be skipped with a 304. With a path set it is persisted to disk.
+type validatorStore struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]feedValidators
+	dirty   bool
+}
+
+func newValidatorStore() *validatorStore {
+	return &validatorStore{
+		entries: make(map[string]feedValidators),
+	}
+}
+
+// loadValidatorStore returns a store persisted at path, loading any validators
+// saved by a previous run. On error an empty store for path is returned too.
+func loadValidatorStore(path string) (*validatorStore, error) {
+	store := newValidatorStore()
+	store.path = path
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil // First run
+	}
+	if err != nil {
+		return store, fmt.Errorf("failed to read feed validators: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &store.entries); err != nil {
+		store.entries = make(map[string]feedValidators)
+		return store, fmt.Errorf("failed to unmarshal feed validators: %w", err)
+	}
+	if store.entries == nil {
+		store.entries = make(map[string]feedValidators) // File contained null
+	}
+
+	return store, nil
+}
+
+// apply adds the conditional request headers remembered for url to req
+func (s *validatorStore) apply(url string, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	validators, ok := s.entries[url]
+	if !ok {
+		return
+	}
+	if validators.ETag != "" {
+		req.Header.Set("If-None-Match", validators.ETag)
+	}
+	if validators.LastModified != "" {
+		req.Header.Set("If-Modified-Since", validators.LastModified)
+	}
+}
+
+// record remembers the validators of a successful response
+func (s *validatorStore) record(url string, header http.Header) {
+	validators := feedValidators{
+		ETag:         header.Get("ETag"),
+		LastModified: header.Get("Last-Modified"),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if validators == (feedValidators{}) {
+		if _, ok := s.entries[url]; ok {
+			delete(s.entries, url)
+			s.dirty = true
+		}
+		return
+	}
+	if s.entries[url] != validators {
+		s.entries[url] = validators
+		s.dirty = true
+	}
+}
+
+// forget drops validators so the next request for these URLs fetches the full feed
+func (s *validatorStore) forget(urls ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, url := range urls {
+		if _, ok := s.entries[url]; ok {
+			delete(s.entries, url)
+			s.dirty = true
+		}
+	}
+}
+
+// persist writes the validators to disk if they changed
+func (s *validatorStore) persist() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path == "" || !s.dirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal feed validators: %w", err)
+	}
+
+	if err := storage.WriteFileAtomic(s.path, data); err != nil {
+		return err
+	}
+
+	s.dirty = false
+	return nil
+}
@@ -0,0 +1,222 @@
+package poller
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/testutil"
+)
+
+func TestValidatorStore_RecordApplyForget(t *testing.T) {
+	store := newValidatorStore()
+	url := "https://example.com/feed"
+
+	header := http.Header{}
+	header.Set("ETag", `"abc"`)
+	header.Set("Last-Modified", "Wed, 01 Jan 2025 12:00:00 GMT")
+	store.record(url, header)
+
+	req, _ := http.NewRequest("GET", url, nil)
+	store.apply(url, req)
+
+	if req.Header.Get("If-None-Match") != `"abc"` {
+		t.Errorf("Expected If-None-Match \"abc\", got %q", req.Header.Get("If-None-Match"))
+	}
+	if req.Header.Get("If-Modified-Since") != "Wed, 01 Jan 2025 12:00:00 GMT" {
+		t.Errorf("Expected If-Modified-Since to be set, got %q", req.Header.Get("If-Modified-Since"))
+	}
+
+	store.forget(url)
+
+	req, _ = http.NewRequest("GET", url, nil)
+	store.apply(url, req)
+	if req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		t.Error("Expected no conditional headers after forget")
+	}
+}
+
+func TestValidatorStore_PersistAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "validators.json")
+
+	store, err := loadValidatorStore(path)
+	if err != nil {
+		t.Fatalf("loadValidatorStore failed: %v", err)
+	}
+
+	header := http.Header{}
+	header.Set("ETag", `"v1"`)
+	store.record("https://example.com/feed", header)
+
+	if err := store.persist(); err != nil {
+		t.Fatalf("persist failed: %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("Temp file should not exist after successful write")
+	}
+
+	reloaded, err := loadValidatorStore(path)
+	if err != nil {
+		t.Fatalf("loadValidatorStore failed: %v", err)
+	}
+	if reloaded.entries["https://example.com/feed"].ETag != `"v1"` {
+		t.Errorf("Expected ETag to survive reload, got %+v", reloaded.entries)
+	}
+}
+
+func TestValidatorStore_LoadCorruptedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "validators.json")
+	os.WriteFile(path, []byte("{invalid"), 0644)
+
+	store, err := loadValidatorStore(path)
+	if err == nil {
+		t.Error("Expected error for corrupted file, got nil")
+	}
+	if store == nil || len(store.entries) != 0 {
+		t.Fatal("Expected an empty usable store on error")
+	}
+
+	// The store must still accept new validators
+	header := http.Header{}
+	header.Set("ETag", `"v1"`)
+	store.record("https://example.com/feed", header)
+}
+
+func TestValidatorStore_LoadNullFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "validators.json")
+	os.WriteFile(path, []byte("null"), 0644)
+
+	store, err := loadValidatorStore(path)
+	if err != nil {
+		t.Fatalf("loadValidatorStore failed: %v", err)
+	}
+
+	header := http.Header{}
+	header.Set("ETag", `"v1"`)
+	store.record("https://example.com/feed", header)
+}
+
+// conditionalFeedServer serves a single-page feed with an ETag and answers
+// matching If-None-Match requests with 304
+type conditionalFeedServer struct {
+	*httptest.Server
+	mu          sync.Mutex
+	etag        string
+	conditional int
+	notModified int
+}
+
+func newConditionalFeedServer(t *testing.T) *conditionalFeedServer {
+	s := &conditionalFeedServer{etag: `"v1"`}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if r.Header.Get("If-None-Match") != "" {
+			s.conditional++
+		}
+		if r.Header.Get("If-None-Match") == s.etag {
+			s.notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", s.etag)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(feedPageJSON([]string{"r1", "r2"}, "")))
+	}))
+
+	originalBaseURL := feedBaseURL
+	feedBaseURL = s.URL
+	t.Cleanup(func() {
+		feedBaseURL = originalBaseURL
+		s.Close()
+	})
+	return s
+}
+
+func TestPoller_NotModifiedSkipsStorage(t *testing.T) {
+	server := newConditionalFeedServer(t)
+
+	storage := testutil.NewMockStorage()
+	var buf testutil.SafeBuffer
+	poller := NewPoller(storage, log.New(&buf, "", 0), []models.App{{ID: "123"}}, time.Second)
+
+	if _, err := poller.fetchAndStore(models.App{ID: "123"}, "us"); err != nil {
+		t.Fatalf("First fetchAndStore failed: %v", err)
+	}
+
+	// Any storage write on the second poll would now fail
+	storage.SetSaveError(errors.New("unexpected storage write"))
+
+	if _, err := poller.fetchAndStore(models.App{ID: "123"}, "us"); err != nil {
+		t.Fatalf("Second fetchAndStore failed: %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.notModified != 1 {
+		t.Errorf("Expected 1 not-modified response, got %d", server.notModified)
+	}
+	if !strings.Contains(buf.String(), "No changes for app 123 (us)") {
+		t.Errorf("Expected no-change log message, got: %s", buf.String())
+	}
+}
+
+func TestPoller_ValidatorsSurviveRestart(t *testing.T) {
+	server := newConditionalFeedServer(t)
+	path := filepath.Join(t.TempDir(), "validators.json")
+
+	first := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), []models.App{{ID: "123"}}, time.Second,
+		WithValidatorStore(path))
+	if _, err := first.fetchAndStore(models.App{ID: "123"}, "us"); err != nil {
+		t.Fatalf("fetchAndStore failed: %v", err)
+	}
+
+	second := NewPoller(testutil.NewMockStorage(), log.New(io.Discard, "", 0), []models.App{{ID: "123"}}, time.Second,
+		WithValidatorStore(path))
+	if _, err := second.fetchAndStore(models.App{ID: "123"}, "us"); err != nil {
+		t.Fatalf("fetchAndStore after restart failed: %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.notModified != 1 {
+		t.Errorf("Expected restarted poller to send a conditional request, got %d not-modified responses", server.notModified)
+	}
+}
+
+func TestPoller_StorageErrorForgetsValidators(t *testing.T) {
+	server := newConditionalFeedServer(t)
+
+	storage := testutil.NewMockStorage()
+	storage.SetSaveError(errors.New("disk full"))
+	poller := NewPoller(storage, log.New(io.Discard, "", 0), []models.App{{ID: "123"}}, time.Second)
+
+	if _, err := poller.fetchAndStore(models.App{ID: "123"}, "us"); err == nil {
+		t.Fatal("Expected storage error, got nil")
+	}
+
+	storage.SetSaveError(nil)
+	if _, err := poller.fetchAndStore(models.App{ID: "123"}, "us"); err != nil {
+		t.Fatalf("fetchAndStore failed: %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.conditional != 0 {
+		t.Errorf("Expected the retry after a storage error to fetch the full feed, got %d conditional requests", server.conditional)
+	}
+	if storage.GetSavedReviewCount() != 2 {
+		t.Errorf("Expected 2 reviews stored, got %d", storage.GetSavedReviewCount())
+	}
+}
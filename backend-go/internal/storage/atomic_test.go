@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "data.json")
+
+	if err := WriteFileAtomic(path, []byte("first")); err != nil {
+		t.Fatalf("WriteFileAtomic failed: %v", err)
+	}
+	if err := WriteFileAtomic(path, []byte("second")); err != nil {
+		t.Fatalf("WriteFileAtomic failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "second" {
+		t.Errorf("Expected the file to be replaced, got %q (%v)", data, err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("Expected temp file to be renamed")
+	}
+}
+
+func TestWriteFileAtomic_KeepsFileOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+	os.WriteFile(path, []byte("kept"), 0644)
+
+	// A directory in place of the temp file makes the write fail
+	os.Mkdir(path+".tmp", 0755)
+	if err := WriteFileAtomic(path, []byte("lost")); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	if data, _ := os.ReadFile(path); string(data) != "kept" {
+		t.Errorf("Expected the previous content to stay, got %q", data)
+	}
+}
@@ -5,6 +5,7 @@ import (
     "fmt"
     "os"
     "path/filepath"
+    "strings"
     "sync"
     "time"
 
@@ -15,6 +16,10 @@ type FileStorage struct {
 	filepath string
 	mu       sync.RWMutex
 	reviews  map[string]models.Review
+	// history holds the revisions of edited reviews, oldest first. Reviews
+	// that were never edited have no entry.
+	history      map[string][]models.ReviewRevision
+	historyDirty bool
 }
 
 // Verify that FileStorage implements Storage interface at compile time
@@ -30,21 +35,54 @@ func NewFileStorage(filePath string) (*FileStorage, error) {
     return &FileStorage{
         filepath: filePath,
         reviews:  make(map[string]models.Review),
+        history:  make(map[string][]models.ReviewRevision),
     }, nil
 }
 
-// SaveReviews adds new reviews to storage and persists to disk
+// SaveReviews adds new reviews to storage and persists to disk.
+// Known reviews whose content, title or rating changed are recorded as edits;
+// unedited ones keep their FetchedAt, so it tells when their current version
+// was first seen. Developer responses are kept, the feeds do not carry them.
 func (fs *FileStorage) SaveReviews(reviews []models.Review) error {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
 
 	for _, review := range reviews {
+		if existing, ok := fs.reviews[review.ID]; ok {
+			if existing.EditedIn(review) {
+				fs.recordEdit(existing, review)
+			} else if !existing.FetchedAt.IsZero() {
+				review.FetchedAt = existing.FetchedAt
+			}
+			if review.Response == nil {
+				review.Response = existing.Response
+			}
+			if review.ResponseSyncedAt == nil {
+				review.ResponseSyncedAt = existing.ResponseSyncedAt
+			}
+		}
 		fs.reviews[review.ID] = review
 	}
 
 	return fs.persist()
 }
 
+// recordEdit appends the new version of an edited review to its history,
+// starting the history with the previous version, as first fetched, on the first edit
+func (fs *FileStorage) recordEdit(previous, edited models.Review) {
+	revisions := fs.history[edited.ID]
+	if len(revisions) == 0 {
+		revisions = append(revisions, previous.Revision(previous.FetchedAt))
+	}
+
+	recordedAt := edited.FetchedAt
+	if recordedAt.IsZero() {
+		recordedAt = time.Now()
+	}
+	fs.history[edited.ID] = append(revisions, edited.Revision(recordedAt))
+	fs.historyDirty = true
+}
+
 func (fs *FileStorage) persist() error {
     // Convert map to slice for JSON serialization
     reviewSlice := make([]models.Review, 0, len(fs.reviews))
@@ -58,20 +96,59 @@ func (fs *FileStorage) persist() error {
         return fmt.Errorf("failed to marshal reviews: %w", err)
     }
 
-    // Atomic write: write to temp file, then rename
-    // This prevents corruption if the process crashes mid-write
-    tempFile := fs.filepath + ".tmp"
-    
-    if err := os.WriteFile(tempFile, data, 0644); err != nil {
-        return fmt.Errorf("failed to write temp file: %w", err)
+    if err := WriteFileAtomic(fs.filepath, data); err != nil {
+        return err
     }
 
-    // Atomic rename - this is atomic on most filesystems
-    if err := os.Rename(tempFile, fs.filepath); err != nil {
-        return fmt.Errorf("failed to rename temp file: %w", err)
-    }
+    return fs.persistHistory()
+}
 
-    return nil
+// historyPath returns the file revision histories are kept in, next to the
+// reviews file (e.g. data/reviews_history.json)
+func (fs *FileStorage) historyPath() string {
+	ext := filepath.Ext(fs.filepath)
+	return strings.TrimSuffix(fs.filepath, ext) + "_history" + ext
+}
+
+// persistHistory writes the revision histories if they changed
+func (fs *FileStorage) persistHistory() error {
+	if !fs.historyDirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(fs.history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal review history: %w", err)
+	}
+
+	if err := WriteFileAtomic(fs.historyPath(), data); err != nil {
+		return err
+	}
+
+	fs.historyDirty = false
+	return nil
+}
+
+// loadHistory reads the revision histories, if any were saved
+func (fs *FileStorage) loadHistory() error {
+	fs.history = make(map[string][]models.ReviewRevision)
+
+	data, err := os.ReadFile(fs.historyPath())
+	if os.IsNotExist(err) {
+		return nil // No review has been edited yet
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read review history: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &fs.history); err != nil {
+		fs.history = make(map[string][]models.ReviewRevision)
+		return fmt.Errorf("failed to unmarshal review history: %w", err)
+	}
+	if fs.history == nil {
+		fs.history = make(map[string][]models.ReviewRevision) // File contained null
+	}
+	return nil
 }
 
 // LoadState loads reviews from disk into memory
@@ -103,7 +180,7 @@ func (fs *FileStorage) LoadState() error {
         fs.reviews[review.ID] = review
     }
 
-    return nil
+    return fs.loadHistory()
 }
 
 // SaveState explicitly persists current state (called on shutdown)
@@ -143,4 +220,86 @@ func (fs *FileStorage) GetAllReviews() ([]models.Review, error) {
     }
 
     return result, nil
-}
\ No newline at end of file
+}
+
+// HasReview reports whether a review with the given ID is stored
+func (fs *FileStorage) HasReview(id string) bool {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	_, exists := fs.reviews[id]
+	return exists
+}
+
+// GetReviewHistory returns every known version of a review, oldest first.
+// A review that was never edited has a single revision.
+func (fs *FileStorage) GetReviewHistory(id string) ([]models.ReviewRevision, error) {
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	review, exists := fs.reviews[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	if revisions := fs.history[id]; len(revisions) > 0 {
+		return append([]models.ReviewRevision(nil), revisions...), nil
+	}
+	return []models.ReviewRevision{review.Revision(review.FetchedAt)}, nil
+}
+
+// MarkRemoved flags reviews that disappeared from the store. They are kept,
+// and saving a review again (it reappeared) clears the flag.
+func (fs *FileStorage) MarkRemoved(ids []string, at time.Time) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	changed := false
+	for _, id := range ids {
+		review, exists := fs.reviews[id]
+		if !exists || review.RemovedAt != nil {
+			continue
+		}
+		removedAt := at
+		review.RemovedAt = &removedAt
+		fs.reviews[id] = review
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+	return fs.persist()
+}
+
+// SetResponses sets the developer responses of stored reviews and persists
+// them if any changed. Reviews synced before keep their marker while their
+// response is unchanged, so an unchanged sync does not rewrite the file.
+func (fs *FileStorage) SetResponses(responses map[string]*models.DeveloperResponse, syncedAt time.Time) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	changed := false
+	for id, response := range responses {
+		review, exists := fs.reviews[id]
+		if !exists || (review.ResponseSyncedAt != nil && sameResponse(review.Response, response)) {
+			continue
+		}
+		review.Response = response
+		review.ResponseSyncedAt = &syncedAt
+		fs.reviews[id] = review
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+	return fs.persist()
+}
+
+func sameResponse(a, b *models.DeveloperResponse) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Body == b.Body && a.State == b.State && a.LastModifiedAt.Equal(b.LastModifiedAt)
+}
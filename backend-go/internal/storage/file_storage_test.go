@@ -1,6 +1,7 @@
 package storage
 
 import (
+    "errors"
     "fmt"
     "os"
     "path/filepath"
@@ -770,4 +771,248 @@ func containsHelper(str, substr string) bool {
         }
     }
     return false
-}
\ No newline at end of file
+}
+
+func TestFileStorage_HasReview(t *testing.T) {
+    tempDir := t.TempDir()
+    testFile := filepath.Join(tempDir, "test_reviews.json")
+
+    storage, _ := NewFileStorage(testFile)
+
+    if storage.HasReview("review1") {
+        t.Error("Expected review1 to be missing from empty storage")
+    }
+
+    storage.SaveReviews([]models.Review{{ID: "review1", AppID: "123"}})
+
+    if !storage.HasReview("review1") {
+        t.Error("Expected review1 to be stored")
+    }
+    if storage.HasReview("review2") {
+        t.Error("Expected review2 to be missing")
+    }
+}
+
+func TestFileStorage_ReviewMetadataPersisted(t *testing.T) {
+    tempDir := t.TempDir()
+    testFile := filepath.Join(tempDir, "test_reviews.json")
+
+    storage, _ := NewFileStorage(testFile)
+    storage.SaveReviews([]models.Review{{
+        ID:        "review1",
+        AppID:     "123",
+        Title:     "Great",
+        Version:   "4.2.0",
+        VoteSum:   3,
+        VoteCount: 5,
+        Link:      "https://itunes.apple.com/us/review?id=123",
+    }})
+
+    reloaded, _ := NewFileStorage(testFile)
+    if err := reloaded.LoadState(); err != nil {
+        t.Fatalf("LoadState failed: %v", err)
+    }
+
+    reviews, _ := reloaded.GetAllReviews()
+    if len(reviews) != 1 {
+        t.Fatalf("Expected 1 review, got %d", len(reviews))
+    }
+    review := reviews[0]
+    if review.Title != "Great" || review.Version != "4.2.0" || review.VoteSum != 3 || review.VoteCount != 5 ||
+        review.Link != "https://itunes.apple.com/us/review?id=123" {
+        t.Errorf("Review metadata not persisted: %+v", review)
+    }
+}
+
+func TestFileStorage_LoadStateWithoutMetadata(t *testing.T) {
+    tempDir := t.TempDir()
+    testFile := filepath.Join(tempDir, "test_reviews.json")
+
+    // Reviews stored before title, version, votes and link were captured
+    oldFormat := `[{"id":"review1","app_id":"123","author":"John","content":"Nice","rating":4,` +
+        `"submitted_at":"2023-01-15T10:30:00Z","fetched_at":"2023-01-15T11:00:00Z"}]`
+    os.WriteFile(testFile, []byte(oldFormat), 0644)
+
+    storage, _ := NewFileStorage(testFile)
+    if err := storage.LoadState(); err != nil {
+        t.Fatalf("LoadState failed for old format: %v", err)
+    }
+
+    reviews, _ := storage.GetAllReviews()
+    if len(reviews) != 1 || reviews[0].Content != "Nice" || reviews[0].Rating != 4 {
+        t.Fatalf("Expected old review to load, got %+v", reviews)
+    }
+    if reviews[0].Title != "" || reviews[0].VoteCount != 0 {
+        t.Errorf("Expected missing metadata to stay empty, got %+v", reviews[0])
+    }
+}
+
+func TestFileStorage_TracksReviewEdits(t *testing.T) {
+    tempDir := t.TempDir()
+    testFile := filepath.Join(tempDir, "reviews.json")
+
+    storage, _ := NewFileStorage(testFile)
+
+    first := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+    original := models.Review{ID: "review1", AppID: "123", Content: "Crashes on start", Rating: 1, FetchedAt: first}
+    storage.SaveReviews([]models.Review{original})
+
+    // Votes change without an edit
+    unchanged := original
+    unchanged.VoteCount = 3
+    unchanged.FetchedAt = first.Add(time.Hour)
+    storage.SaveReviews([]models.Review{unchanged})
+
+    history, err := storage.GetReviewHistory("review1")
+    if err != nil {
+        t.Fatalf("GetReviewHistory failed: %v", err)
+    }
+    if len(history) != 1 {
+        t.Fatalf("Expected a single revision before any edit, got %d", len(history))
+    }
+
+    edited := unchanged
+    edited.Content = "Fixed in the latest update"
+    edited.Rating = 4
+    edited.FetchedAt = first.Add(2 * time.Hour)
+    storage.SaveReviews([]models.Review{edited})
+
+    retitled := edited
+    retitled.Title = "Works now"
+    retitled.FetchedAt = first.Add(3 * time.Hour)
+    storage.SaveReviews([]models.Review{retitled})
+
+    history, _ = storage.GetReviewHistory("review1")
+    if len(history) != 3 {
+        t.Fatalf("Expected 3 revisions, got %d: %+v", len(history), history)
+    }
+    if history[0].Rating != 1 || !history[0].RecordedAt.Equal(first) {
+        t.Errorf("Expected the original version first, got %+v", history[0])
+    }
+    if history[1].Rating != 4 || !history[1].RecordedAt.Equal(first.Add(2*time.Hour)) {
+        t.Errorf("Expected the rating edit second, got %+v", history[1])
+    }
+    if history[2].Title != "Works now" {
+        t.Errorf("Expected the title edit last, got %+v", history[2])
+    }
+
+    // History survives a restart
+    reloaded, _ := NewFileStorage(testFile)
+    if err := reloaded.LoadState(); err != nil {
+        t.Fatalf("LoadState failed: %v", err)
+    }
+    history, _ = reloaded.GetReviewHistory("review1")
+    if len(history) != 3 {
+        t.Errorf("Expected 3 revisions after reload, got %d", len(history))
+    }
+    if _, err := os.Stat(filepath.Join(tempDir, "reviews_history.json")); err != nil {
+        t.Errorf("Expected history file next to the reviews: %v", err)
+    }
+}
+
+func TestFileStorage_GetReviewHistoryNotFound(t *testing.T) {
+    storage, _ := NewFileStorage(filepath.Join(t.TempDir(), "reviews.json"))
+
+    if _, err := storage.GetReviewHistory("missing"); !errors.Is(err, ErrNotFound) {
+        t.Errorf("Expected ErrNotFound, got %v", err)
+    }
+}
+
+func TestFileStorage_MarkRemoved(t *testing.T) {
+    testFile := filepath.Join(t.TempDir(), "reviews.json")
+    storage, _ := NewFileStorage(testFile)
+    storage.SaveReviews([]models.Review{{ID: "review1", AppID: "123"}, {ID: "review2", AppID: "123"}})
+
+    removedAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+    if err := storage.MarkRemoved([]string{"review1", "missing"}, removedAt); err != nil {
+        t.Fatalf("MarkRemoved failed: %v", err)
+    }
+
+    // Marking again keeps the original timestamp
+    storage.MarkRemoved([]string{"review1"}, removedAt.Add(time.Hour))
+
+    reloaded, _ := NewFileStorage(testFile)
+    if err := reloaded.LoadState(); err != nil {
+        t.Fatalf("LoadState failed: %v", err)
+    }
+    reviews, _ := reloaded.GetAllReviews()
+    if len(reviews) != 2 {
+        t.Fatalf("Expected removed reviews to be kept, got %d reviews", len(reviews))
+    }
+    for _, review := range reviews {
+        switch review.ID {
+        case "review1":
+            if review.RemovedAt == nil || !review.RemovedAt.Equal(removedAt) {
+                t.Errorf("Expected review1 removed at %v, got %v", removedAt, review.RemovedAt)
+            }
+        case "review2":
+            if review.RemovedAt != nil {
+                t.Errorf("Expected review2 to stay listed, got removed at %v", review.RemovedAt)
+            }
+        }
+    }
+
+    // A review that shows up in the feed again is listed again
+    reloaded.SaveReviews([]models.Review{{ID: "review1", AppID: "123"}})
+    reviews, _ = reloaded.GetAllReviews()
+    for _, review := range reviews {
+        if review.ID == "review1" && review.RemovedAt != nil {
+            t.Error("Expected a saved review to clear its removed flag")
+        }
+    }
+}
+
+func TestFileStorage_SetResponses(t *testing.T) {
+    testFile := filepath.Join(t.TempDir(), "reviews.json")
+    storage, _ := NewFileStorage(testFile)
+    storage.SaveReviews([]models.Review{{ID: "review1", AppID: "123"}, {ID: "review2", AppID: "123"}})
+
+    response := &models.DeveloperResponse{
+        Body:           "Thanks for the feedback!",
+        State:          models.ResponseStatePublished,
+        LastModifiedAt: time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC),
+    }
+    syncedAt := time.Date(2025, 1, 3, 9, 0, 0, 0, time.UTC)
+    if err := storage.SetResponses(map[string]*models.DeveloperResponse{"review1": response, "missing": response}, syncedAt); err != nil {
+        t.Fatalf("SetResponses failed: %v", err)
+    }
+
+    // The feeds carry no responses, saving a review again keeps it
+    storage.SaveReviews([]models.Review{{ID: "review1", AppID: "123", Content: "edited"}})
+
+    reloaded, _ := NewFileStorage(testFile)
+    if err := reloaded.LoadState(); err != nil {
+        t.Fatalf("LoadState failed: %v", err)
+    }
+    reviews, _ := reloaded.GetAllReviews()
+    if len(reviews) != 2 {
+        t.Fatalf("Expected unknown IDs to be ignored, got %d reviews", len(reviews))
+    }
+    for _, review := range reviews {
+        switch review.ID {
+        case "review1":
+            if review.Response == nil || review.Response.Body != response.Body || !review.Response.LastModifiedAt.Equal(response.LastModifiedAt) {
+                t.Errorf("Expected the response of review1 to be kept, got %+v", review.Response)
+            }
+            if review.ResponseSyncedAt == nil || !review.ResponseSyncedAt.Equal(syncedAt) {
+                t.Errorf("Expected review1 to be marked as synced at %v, got %v", syncedAt, review.ResponseSyncedAt)
+            }
+        case "review2":
+            if review.Response != nil || review.ResponseSyncedAt != nil {
+                t.Errorf("Expected review2 to stay unsynced, got %+v", review)
+            }
+        }
+    }
+
+    // A deleted response is cleared, a review synced without one is marked
+    reloaded.SetResponses(map[string]*models.DeveloperResponse{"review1": nil, "review2": nil}, syncedAt.Add(time.Hour))
+    reviews, _ = reloaded.GetAllReviews()
+    for _, review := range reviews {
+        if review.Response != nil {
+            t.Errorf("Expected no responses left, got %+v on %s", review.Response, review.ID)
+        }
+        if review.ResponseSyncedAt == nil || !review.ResponseSyncedAt.Equal(syncedAt.Add(time.Hour)) {
+            t.Errorf("Expected %s to be marked as synced, got %v", review.ID, review.ResponseSyncedAt)
+        }
+    }
+}
@@ -1,16 +1,29 @@
 package storage
 
 import (
+	"errors"
 	"time"
 
 	"backend/internal/models"
 )
 
+// ErrNotFound is returned when a requested review is not stored
+var ErrNotFound = errors.New("review not found")
+
 // Storage defines the interface for review persistence
 type Storage interface {
 	SaveReviews(reviews []models.Review) error
 	GetRecentReviews(appID string, since time.Duration) ([]models.Review, error)
 	GetAllReviews() ([]models.Review, error)
+	HasReview(id string) bool
+	// GetReviewHistory returns every known version of a review, oldest first
+	GetReviewHistory(id string) ([]models.ReviewRevision, error)
+	// MarkRemoved flags stored reviews as removed from the store at the given time
+	MarkRemoved(ids []string, at time.Time) error
+	// SetResponses sets the developer responses of stored reviews by review ID
+	// and marks them as synced at the given time. A nil response clears it,
+	// unknown IDs are ignored.
+	SetResponses(responses map[string]*models.DeveloperResponse, syncedAt time.Time) error
 	LoadState() error
 	SaveState() error
 }
@@ -12,6 +12,7 @@ import (
 type MockStorage struct {
 	mu                     sync.RWMutex
 	reviews                map[string]models.Review
+	history                map[string][]models.ReviewRevision
 	saveErr                error
 	loadErr                error
 	getRecentReviewsErr    error
@@ -24,6 +25,7 @@ var _ storage.Storage = (*MockStorage)(nil)
 func NewMockStorage() *MockStorage {
 	return &MockStorage{
 		reviews: make(map[string]models.Review),
+		history: make(map[string][]models.ReviewRevision),
 	}
 }
 
@@ -34,6 +36,14 @@ func (m *MockStorage) SaveReviews(reviews []models.Review) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	for _, review := range reviews {
+		if existing, ok := m.reviews[review.ID]; ok {
+			if review.Response == nil {
+				review.Response = existing.Response
+			}
+			if review.ResponseSyncedAt == nil {
+				review.ResponseSyncedAt = existing.ResponseSyncedAt
+			}
+		}
 		m.reviews[review.ID] = review
 	}
 	return nil
@@ -109,6 +119,7 @@ func (m *MockStorage) Reset() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.reviews = make(map[string]models.Review)
+	m.history = make(map[string][]models.ReviewRevision)
 	m.saveErr = nil
 	m.loadErr = nil
 	m.getRecentReviewsErr = nil
@@ -129,4 +140,59 @@ func (m *MockStorage) GetReview(id string) (models.Review, bool) {
 	defer m.mu.RUnlock()
 	review, exists := m.reviews[id]
 	return review, exists
-}
\ No newline at end of file
+}
+
+// GetReviewHistory returns the history set with SetReviewHistory, or the
+// current version of a stored review
+func (m *MockStorage) GetReviewHistory(id string) ([]models.ReviewRevision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if revisions, ok := m.history[id]; ok {
+		return revisions, nil
+	}
+	review, exists := m.reviews[id]
+	if !exists {
+		return nil, storage.ErrNotFound
+	}
+	return []models.ReviewRevision{review.Revision(review.FetchedAt)}, nil
+}
+
+// SetReviewHistory sets the revisions returned for a review
+func (m *MockStorage) SetReviewHistory(id string, revisions []models.ReviewRevision) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.history[id] = revisions
+}
+
+func (m *MockStorage) MarkRemoved(ids []string, at time.Time) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		if review, exists := m.reviews[id]; exists && review.RemovedAt == nil {
+			removedAt := at
+			review.RemovedAt = &removedAt
+			m.reviews[id] = review
+		}
+	}
+	return nil
+}
+
+func (m *MockStorage) SetResponses(responses map[string]*models.DeveloperResponse, syncedAt time.Time) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, response := range responses {
+		if review, exists := m.reviews[id]; exists {
+			review.Response = response
+			review.ResponseSyncedAt = &syncedAt
+			m.reviews[id] = review
+		}
+	}
+	return nil
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"log"
 	"net/http"
 	"os"
@@ -10,19 +9,37 @@ import (
 	"syscall"
 	"time"
 
+	"backend/internal/appstoreconnect"
+	"backend/internal/config"
 	"backend/internal/handler"
+	"backend/internal/httpclient"
+	"backend/internal/leader"
+	"backend/internal/metadata"
 	"backend/internal/poller"
 	"backend/internal/storage"
 )
 
+// Files shared by the service and its subcommands
+const (
+	configPath      = "config/apps.json"
+	storageFilePath = "data/reviews.json"
+	metadataPath    = "data/app_metadata.json"
+)
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "backfill" {
+		os.Exit(runBackfill(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "reparse" {
+		os.Exit(runReparse(os.Args[2:]))
+	}
+
 	// TODO: move to slog to have more control (e.g. log levels)
 	logger := log.New(os.Stdout, "[POLLER] ", log.LstdFlags)
 
 	logger.Println("Starting App Store Review Poller...")
 
 	// Initialize storage
-	storageFilePath := "data/reviews.json"
 	store, err := storage.NewFileStorage(storageFilePath)
 	if err != nil {
 		logger.Fatalf("Failed to create storage: %v", err)
@@ -37,24 +54,121 @@ func main() {
 	}
 
 	// Load config
-	config, err := loadConfig("config/apps.json")
+	cfg, err := config.Load(configPath)
 	if err != nil {
 		logger.Fatalf("Failed to load config: %v", err)
 	}
 
-	// Start reviewPoller
-	pollInterval := 5 * time.Minute
-	reviewPoller := poller.NewPoller(store, logger, config.Apps, pollInterval)
-	reviewPoller.Start()
+	// All requests to the stores go through the configured proxy, CA bundle, ...
+	httpClient, err := newHTTPClient(cfg, logger)
+	if err != nil {
+		logger.Fatalf("Failed to create HTTP client: %v", err)
+	}
+
+	// The poller only starts once this instance leads, see below
+	pollInterval := time.Duration(cfg.Poller.Interval)
+	pollerOptions := append(newPollerOptions(cfg, httpClient), poller.WithValidatorStore("data/feed_validators.json"))
+	reviewPoller := poller.NewPoller(store, logger, cfg.Apps, pollInterval, pollerOptions...)
+
+	configManager := config.NewManager(configPath, *cfg, reviewPoller, logger)
+
+	// Keep app names, icons, ... from the iTunes Lookup API up to date
+	metadataOptions := []metadata.Option{
+		metadata.WithHTTPClient(httpClient),
+		metadata.WithUserAgent(cfg.HTTP.UserAgent),
+	}
+	if cfg.Metadata.BaseURL != "" {
+		metadataOptions = append(metadataOptions, metadata.WithBaseURL(cfg.Metadata.BaseURL))
+	}
+	appMetadata := metadata.NewRegistry(metadataPath, configManager, logger, metadataOptions...)
+	if err := appMetadata.Load(); err != nil {
+		logger.Printf("Warning: Failed to load app metadata: %v", err)
+	}
+
+	// Attach the developer responses from App Store Connect to iOS reviews
+	var responseSync *appstoreconnect.ResponseSync
+	if connect := cfg.AppStoreConnect; connect.KeyFile != "" {
+		connectOptions := []appstoreconnect.Option{
+			appstoreconnect.WithHTTPClient(httpClient),
+			appstoreconnect.WithUserAgent(cfg.HTTP.UserAgent),
+		}
+		if connect.BaseURL != "" {
+			connectOptions = append(connectOptions, appstoreconnect.WithBaseURL(connect.BaseURL))
+		}
+		connectClient, err := appstoreconnect.NewClient(appstoreconnect.Config{
+			KeyFile:  connect.KeyFile,
+			KeyID:    connect.KeyID,
+			IssuerID: connect.IssuerID,
+		}, connectOptions...)
+		if err != nil {
+			logger.Printf("Warning: %v, developer responses will not be synced", err)
+		} else {
+			responseSync = appstoreconnect.NewResponseSync(connectClient, configManager, store, logger)
+		}
+	}
+
+	service := &replica{
+		logger:            logger,
+		config:            configManager,
+		store:             store,
+		poller:            reviewPoller,
+		metadata:          appMetadata,
+		metadataPath:      metadataPath,
+		metadataInterval:  time.Duration(cfg.Metadata.RefreshInterval),
+		responses:         responseSync,
+		responsesInterval: time.Duration(cfg.AppStoreConnect.SyncInterval),
+		modTimes:          make(map[string]time.Time),
+	}
+	service.changed(configPath) // All were loaded above
+	service.changed(storageFilePath)
+	service.changed(metadataPath)
+
+	handlerOptions := []handler.Option{
+		handler.WithPoller(reviewPoller),
+		handler.WithApps(configManager),
+		handler.WithMetadata(appMetadata),
+	}
+	if responseSync != nil {
+		handlerOptions = append(handlerOptions, handler.WithResponses())
+	}
+
+	// With several instances on the same data directory only the elected
+	// leader polls and writes, the others serve reads
+	var elector *leader.Elector
+	electionStop := make(chan struct{})
+	electionDone := make(chan struct{})
+	if cfg.Leader.Enabled {
+		id := cfg.Leader.ID
+		if id == "" {
+			id = leader.DefaultID()
+		}
+		elector = leader.NewElector(cfg.Leader.LockFile, id, time.Duration(cfg.Leader.LeaseDuration), logger)
+		service.leased = true
+		handlerOptions = append(handlerOptions, handler.WithLeadership(elector))
+
+		logger.Printf("Leader election enabled, competing for %s as %s", cfg.Leader.LockFile, id)
+		go func() {
+			elector.Run(time.Duration(cfg.Leader.RenewInterval), service, electionStop)
+			close(electionDone)
+		}()
+	} else {
+		service.StartLeading()
+	}
 
 	// Setup HTTP handlers
-	h := handler.NewHandler(store)
+	h := handler.NewHandler(store, handlerOptions...)
 	mux := http.NewServeMux()
 
 	// API endpoints
 	mux.HandleFunc("/api/reviews", h.GetRecentReviews)
+	mux.HandleFunc("/api/reviews/{id}/history", h.GetReviewHistory)
 	mux.HandleFunc("/api/health", h.HealthCheck)
 	mux.HandleFunc("/api/average-rating", h.GetAverageRating)
+	mux.HandleFunc("/api/apps", h.HandleApps)
+	mux.HandleFunc("/api/apps/{id}", h.HandleApp)
+	mux.HandleFunc("/api/apps/{id}/poll", h.PollApp)
+	mux.HandleFunc("/api/poll", h.PollAll)
+	mux.HandleFunc("/api/poll-status", h.GetPollStatus)
 
 	// Wrap with CORS middleware
 	corsHandler := enableCORS(mux)
@@ -76,6 +190,21 @@ func main() {
 		}
 	}()
 
+	// Reload the config on SIGHUP and, if enabled, whenever the file changes
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			logger.Println("SIGHUP received, reloading config...")
+			if err := configManager.Reload(); err != nil {
+				logger.Printf("Error reloading config, keeping the current one: %v", err)
+			}
+		}
+	}()
+
+	watchStop := make(chan struct{})
+	go configManager.Watch(watchStop)
+
 	// Setup graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -83,13 +212,19 @@ func main() {
 	// Wait for interrupt signal
 	<-sigChan
 	logger.Println("\nShutdown signal received, cleaning up...")
+	signal.Stop(hupChan)
+	close(watchStop)
 
-	logger.Println("Stopping poller...")
-	reviewPoller.Stop()
-
-	log.Println("Saving final state...")
-	if err := store.SaveState(); err != nil {
-		log.Printf("Error saving state: %v", err)
+	// Give running polls a moment to finish, then cancel their requests and
+	// save the state. The elector does so until the lease expires and then
+	// releases it, so a follower takes over right away.
+	if elector != nil {
+		close(electionStop)
+		<-electionDone
+	} else {
+		leaderCtx, cancelLeader := context.WithTimeout(context.Background(), 10*time.Second)
+		service.StopLeading(leaderCtx)
+		cancelLeader()
 	}
 
 	// Shutdown server
@@ -103,23 +238,33 @@ func main() {
 	logger.Println("Shutdown complete")
 }
 
-type Config struct {
-	Apps []string `json:"apps"`
-}
-
-func loadConfig(filepath string) (*Config, error) {
-	file, err := os.Open(filepath)
+// newHTTPClient builds the client shared by all outgoing requests, recording
+// or replaying its responses if the config says so
+func newHTTPClient(cfg *config.Config, logger *log.Logger) (*http.Client, error) {
+	client, err := httpclient.New(cfg.HTTP.ClientConfig())
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
-	var config Config
-	if err := json.NewDecoder(file).Decode(&config); err != nil {
-		return nil, err
+	switch recording := cfg.HTTP.Recording; recording.Mode {
+	case config.RecordingModeRecord:
+		httpclient.Record(client, recording.Dir, logger)
+	case config.RecordingModeReplay:
+		if err := httpclient.Replay(client, recording.Dir, logger); err != nil {
+			logger.Printf("Warning: %v, replaying without recordings", err)
+		}
 	}
+	return client, nil
+}
 
-	return &config, nil
+// newPollerOptions returns the poller options of the config, sending the
+// requests through client
+func newPollerOptions(cfg *config.Config, client *http.Client) []poller.Option {
+	options := []poller.Option{
+		poller.WithHTTPClient(client),
+		poller.WithUserAgent(cfg.HTTP.UserAgent),
+	}
+	return append(options, cfg.Poller.Options()...)
 }
 
 // enableCORS adds CORS headers to allow frontend access
@@ -127,7 +272,7 @@ func enableCORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Allow requests from frontend
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 
 		// Handle preflight requests
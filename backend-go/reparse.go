@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/httpclient"
+	"backend/internal/poller"
+	"backend/internal/storage"
+)
+
+// runReparse implements the reparse subcommand: it rebuilds the storage file
+// from every feed page recorded in http.recording mode "record", e.g. after a
+// parser fix, without starting the HTTP server or the poller. The storage
+// file must not exist yet, so move it aside first. With leader election the
+// reparse refuses to run while an instance holds the lease.
+//
+//	backend reparse [-dir data/recordings] [-out data/reviews.json]
+func runReparse(args []string) int {
+	flags := flag.NewFlagSet("reparse", flag.ExitOnError)
+	dir := flags.String("dir", "", "directory of the recordings, defaults to http.recording.dir")
+	out := flags.String("out", storageFilePath, "storage file to rebuild, must not exist yet")
+	flags.Parse(args)
+
+	logger := log.New(os.Stdout, "[REPARSE] ", log.LstdFlags)
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		logger.Printf("Failed to load config: %v", err)
+		return 1
+	}
+	if *dir == "" {
+		*dir = cfg.HTTP.Recording.Dir
+	}
+	if *dir == "" {
+		fmt.Fprintln(os.Stderr, "reparse: -dir is required without http.recording.dir")
+		flags.Usage()
+		return 2
+	}
+
+	if leaderRunning(cfg, logger) {
+		return 1
+	}
+	if _, err := os.Stat(*out); !os.IsNotExist(err) {
+		logger.Printf("%s already exists, move it aside to rebuild it", *out)
+		return 1
+	}
+
+	recordings, err := httpclient.LoadRecordings(*dir)
+	if err != nil {
+		logger.Printf("Failed to load recordings: %v", err)
+		return 1
+	}
+
+	store, err := storage.NewFileStorage(*out)
+	if err != nil {
+		logger.Printf("Failed to create storage: %v", err)
+		return 1
+	}
+
+	// The poller is only used for its review sources
+	reparser := poller.NewPoller(store, logger, nil, time.Duration(cfg.Poller.Interval), cfg.Poller.Options()...)
+	result, err := reparser.Reparse(recordings)
+
+	logger.Printf("Reparsed %d of %d recordings: %d reviews stored in %s (feed entries: %v)", result.Parsed, len(recordings), result.Reviews, *out, result.Parse)
+	if err != nil {
+		logger.Printf("Reparse incomplete: %v", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"backend/internal/appstoreconnect"
+	"backend/internal/config"
+	"backend/internal/leader"
+	"backend/internal/metadata"
+	"backend/internal/poller"
+	"backend/internal/storage"
+)
+
+// replica runs the parts of the service that write to the data directory:
+// polling, refreshing app metadata, syncing developer responses and saving
+// the state. With leader election only the leader runs them and followers
+// reload what the leader wrote; without it the single instance always leads.
+type replica struct {
+	logger            *log.Logger
+	config            *config.Manager
+	store             *storage.FileStorage
+	poller            *poller.Poller
+	metadata          *metadata.Registry
+	metadataPath      string
+	metadataInterval  time.Duration
+	responses         *appstoreconnect.ResponseSync // nil without App Store Connect
+	responsesInterval time.Duration
+	syncStop          chan struct{}        // Stops the metadata refresh and response sync
+	syncs             sync.WaitGroup       // Running metadata refresh and response sync
+	modTimes          map[string]time.Time // Of the config and data files when last loaded
+	leased            bool                 // Leadership is held through an expiring lease
+}
+
+// Verify that replica implements leader.Candidate at compile time
+var _ leader.Candidate = (*replica)(nil)
+
+// StartLeading picks up what the previous leader wrote and starts polling
+func (r *replica) StartLeading() {
+	r.reload()
+	r.poller.Start()
+
+	r.syncStop = make(chan struct{})
+	r.syncs.Add(1)
+	go func() {
+		defer r.syncs.Done()
+		r.metadata.Run(r.metadataInterval, r.syncStop)
+	}()
+
+	if r.responses != nil {
+		r.syncs.Add(1)
+		go func() {
+			defer r.syncs.Done()
+			r.responses.Run(r.responsesInterval, r.syncStop)
+		}()
+	}
+}
+
+// StopLeading waits until ctx is done for the metadata refresh, the response
+// sync and running polls to finish and saves the state, unless the lease
+// expired meanwhile and another instance may write. Without leader election
+// the state is always saved.
+func (r *replica) StopLeading(ctx context.Context) {
+	close(r.syncStop)
+	synced := make(chan struct{})
+	go func() {
+		r.syncs.Wait()
+		close(synced)
+	}()
+	select {
+	case <-synced:
+	case <-ctx.Done():
+		r.logger.Println("Metadata refresh or response sync did not stop in time")
+	}
+
+	r.logger.Println("Stopping poller...")
+	if err := r.poller.Shutdown(ctx); err != nil {
+		r.logger.Printf("Poller shutdown: %v", err)
+	}
+
+	if r.leased && ctx.Err() != nil {
+		r.logger.Println("Leader lease expired, not saving final state")
+		return
+	}
+	r.logger.Println("Saving final state...")
+	if err := r.store.SaveState(); err != nil {
+		r.logger.Printf("Error saving state: %v", err)
+	}
+}
+
+// Following keeps the reads of a follower up to date with the leader's writes
+func (r *replica) Following(leader.Lease) {
+	r.reload()
+}
+
+// reload loads the tracked apps, reviews and app metadata again if their
+// files changed since they were last loaded, so apps the leader added or
+// removed are not lost on failover
+func (r *replica) reload() {
+	if r.changed(configPath) {
+		if err := r.config.Reload(); err != nil {
+			r.logger.Printf("Error reloading config: %v", err)
+		}
+	}
+	if r.changed(storageFilePath) {
+		if err := r.store.LoadState(); err != nil {
+			r.logger.Printf("Error reloading reviews: %v", err)
+		}
+	}
+	if r.changed(r.metadataPath) {
+		if err := r.metadata.Load(); err != nil {
+			r.logger.Printf("Error reloading app metadata: %v", err)
+		}
+	}
+}
+
+// changed reports whether the file at path was modified since the last call
+func (r *replica) changed(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false // Not written yet
+	}
+	if info.ModTime().Equal(r.modTimes[path]) {
+		return false
+	}
+	r.modTimes[path] = info.ModTime()
+	return true
+}